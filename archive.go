@@ -0,0 +1,238 @@
+package todotxt
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	ys "github.com/1set/gut/ystring"
+)
+
+// Archive appends all completed tasks to io.Writer (most likely for a file called "done.txt"), and removes them from the TaskList.
+// IDs of the remaining tasks are not changed. Comment and blank lines are kept in the TaskList.
+//
+// The TaskList is only modified if all completed tasks are written successfully.
+func (tasklist *TaskList) Archive(writer io.Writer) error {
+	done, remaining := tasklist.splitCompleted()
+	if len(done) == 0 {
+		return nil
+	}
+	if _, err := done.WriteTo(writer); err != nil {
+		return err
+	}
+	*tasklist = remaining
+	return nil
+}
+
+// ArchiveToPath moves all completed tasks from the TaskList to the done file (most likely called "done.txt"),
+// and then writes the remaining tasks to the todo file (most likely called "todo.txt"). IDs of the remaining tasks are not changed.
+//
+// Completed tasks are appended to the done file and synced to disk before the todo file is written. The archive in progress
+// is recorded in a journal next to the done file (e.g. "done.txt.archiving"), which is removed after the todo file is written.
+// So if the process crashes between the two writes, no task is lost, and archiving the unchanged todo file again
+// doesn't duplicate the tasks appended by the interrupted archive. All other completed tasks are appended, even if
+// the done file already has identical lines.
+func (tasklist *TaskList) ArchiveToPath(todoFilename, doneFilename string) error {
+	return defaultParser().ArchiveToPath(todoFilename, doneFilename, tasklist)
+}
+
+// ArchiveToPath moves all completed tasks from the TaskList to the done file (most likely called "done.txt"),
+// and then writes the remaining tasks to the todo file (most likely called "todo.txt"), both with options of the parser.
+// See TaskList.ArchiveToPath() for further information.
+func (p *Parser) ArchiveToPath(todoFilename, doneFilename string, tasklist *TaskList) error {
+	done, remaining := tasklist.splitCompleted()
+	content := []byte(p.formatList(remaining))
+
+	journalFilename := doneFilename + archiveJournalSuffix
+	journal, err := recoverArchive(journalFilename, todoFilename, doneFilename)
+	if err != nil {
+		return err
+	}
+
+	// tasks appended by an interrupted archive are still in the todo file, and not appended again
+	pending := make(map[string]int)
+	for _, line := range journal.lines {
+		pending[line]++
+	}
+	var lines []string
+	for i := range done {
+		if text := p.Format(&done[i]); pending[text] > 0 {
+			pending[text]--
+		} else {
+			lines = append(lines, text)
+		}
+	}
+
+	if len(journal.lines)+len(lines) > 0 {
+		journal.todo = sha256.Sum256(content)
+		journal.lines = append(journal.lines, lines...)
+		if err := writeFileAtomic(journalFilename, []byte(journal.String())); err != nil {
+			return err
+		}
+		if len(lines) > 0 {
+			if err := appendToPath(lines, doneFilename); err != nil {
+				return err
+			}
+		}
+	}
+
+	*tasklist = remaining
+	if err := writeFileAtomic(todoFilename, content); err != nil {
+		return err
+	}
+	if err := os.Remove(journalFilename); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// splitCompleted returns completed tasks and other entries of the TaskList.
+func (tasklist *TaskList) splitCompleted() (done, remaining TaskList) {
+	remaining = TaskList{}
+	for _, t := range *tasklist {
+		if t.IsTask() && t.Completed {
+			t.indent = emptyStr // completed tasks are archived without the indentation of the subtask
+			done = append(done, t)
+		} else {
+			remaining = append(remaining, t)
+		}
+	}
+	return done, remaining
+}
+
+// archiveJournalSuffix is appended to the name of the done file for the journal of ArchiveToPath().
+const archiveJournalSuffix = ".archiving"
+
+// archiveJournal records an archive of ArchiveToPath() in progress, so it can be recovered if the process crashes before the todo file is written.
+type archiveJournal struct {
+	offset int64             // Size of the done file before the tasks were appended.
+	todo   [sha256.Size]byte // SHA-256 hash of the todo file content to be written.
+	lines  []string          // Tasks appended to the done file.
+}
+
+// String returns the journal as text: the offset, the hash in hex and the appended tasks, each on its own line.
+func (j *archiveJournal) String() string {
+	var sb strings.Builder
+	sb.WriteString(strconv.FormatInt(j.offset, 10) + ys.NewLine)
+	sb.WriteString(hex.EncodeToString(j.todo[:]) + ys.NewLine)
+	for _, line := range j.lines {
+		sb.WriteString(line + ys.NewLine)
+	}
+	return sb.String()
+}
+
+// recoverArchive returns the journal of the archive interrupted before the todo file was written, with the tasks appended to the done file,
+// or a new journal without tasks if there's none. A partially appended done file is truncated to its size before the interrupted archive.
+func recoverArchive(journalFilename, todoFilename, doneFilename string) (*archiveJournal, error) {
+	doneSize := int64(0)
+	if info, err := os.Stat(doneFilename); err == nil {
+		doneSize = info.Size()
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadFile(journalFilename)
+	if os.IsNotExist(err) {
+		return &archiveJournal{offset: doneSize}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	journal, err := parseArchiveJournal(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", journalFilename, err)
+	}
+
+	// the todo file was written, only the journal was not removed
+	if todo, err := FingerprintPath(todoFilename); err != nil {
+		return nil, err
+	} else if !todo.IsZero() && todo.Hash == journal.todo {
+		return &archiveJournal{offset: doneSize}, nil
+	}
+
+	if doneSize >= journal.offset {
+		done, err := ioutil.ReadFile(doneFilename)
+		if err != nil {
+			return nil, err
+		}
+		var appended []string
+		for _, line := range strings.Split(string(done[journal.offset:]), "\n") {
+			if text := strings.Trim(line, whitespaces); isNotEmpty(text) {
+				appended = append(appended, text)
+			}
+		}
+		if strings.Join(appended, ys.NewLine) == strings.Join(journal.lines, ys.NewLine) {
+			return journal, nil
+		}
+		if err := os.Truncate(doneFilename, journal.offset); err != nil {
+			return nil, err
+		}
+		return &archiveJournal{offset: journal.offset}, nil
+	}
+	return &archiveJournal{offset: doneSize}, nil
+}
+
+// parseArchiveJournal parses the journal text written by archiveJournal.String().
+func parseArchiveJournal(text string) (*archiveJournal, error) {
+	lines := strings.Split(strings.TrimRight(text, whitespaces), "\n")
+	if len(lines) < 2 {
+		return nil, errors.New("invalid archive journal")
+	}
+	offset, err := strconv.ParseInt(strings.TrimSpace(lines[0]), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive journal: %v", err)
+	}
+	hash, err := hex.DecodeString(strings.TrimSpace(lines[1]))
+	if err != nil || len(hash) != sha256.Size {
+		return nil, errors.New("invalid archive journal: invalid hash")
+	}
+
+	journal := &archiveJournal{offset: offset}
+	copy(journal.todo[:], hash)
+	for _, line := range lines[2:] {
+		if text := strings.Trim(line, whitespaces); isNotEmpty(text) {
+			journal.lines = append(journal.lines, text)
+		}
+	}
+	return journal, nil
+}
+
+// appendToPath appends the lines to the end of the file, and syncs the file to disk.
+func appendToPath(lines []string, filename string) error {
+	file, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0640)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// Check if the file ends with a new line
+	var sb strings.Builder
+	if info, err := file.Stat(); err != nil {
+		return err
+	} else if info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := file.ReadAt(last, info.Size()-1); err != nil {
+			return err
+		}
+		if last[0] != '\n' {
+			sb.WriteString(ys.NewLine)
+		}
+	}
+
+	for _, line := range lines {
+		sb.WriteString(line)
+		sb.WriteString(ys.NewLine)
+	}
+	if _, err := file.WriteString(sb.String()); err != nil {
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		return err
+	}
+	return file.Close()
+}
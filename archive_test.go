@@ -0,0 +1,215 @@
+package todotxt
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write error")
+}
+
+func TestTaskListArchive(t *testing.T) {
+	if err := testTasklist.LoadFromPath(testInputTasklist); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := testTasklist.Archive(failingWriter{}); err == nil {
+		t.Errorf("Expected Archive to fail for failing writer")
+	}
+	testExpected = 63
+	testGot = len(testTasklist)
+	if testGot != testExpected {
+		t.Errorf("Expected TaskList to contain %d tasks after failed archive, but got %d", testExpected, testGot)
+	}
+
+	var buf bytes.Buffer
+	if err := testTasklist.Archive(&buf); err != nil {
+		t.Fatal(err)
+	}
+	testExpected = 30
+	testGot = len(testTasklist)
+	if testGot != testExpected {
+		t.Errorf("Expected TaskList to contain %d tasks, but got %d", testExpected, testGot)
+	}
+	testExpected = 0
+	testGot = len(testTasklist.Filter(FilterCompleted))
+	if testGot != testExpected {
+		t.Errorf("Expected TaskList to contain %d completed tasks, but got %d", testExpected, testGot)
+	}
+
+	done, err := LoadFromReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testExpected = 33
+	testGot = len(done.Filter(FilterCompleted))
+	if testGot != testExpected {
+		t.Errorf("Expected done list to contain %d completed tasks, but got %d", testExpected, testGot)
+	}
+
+	// IDs are stable
+	if task, err := testTasklist.GetTask(3); err != nil {
+		t.Error(err)
+	} else if task.Todo != "Outline chapter 5" {
+		t.Errorf("Expected Task[3] to be kept, but got [%s]", task)
+	}
+
+	// nothing to archive
+	buf.Reset()
+	if err := testTasklist.Archive(failingWriter{}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTaskListArchiveToPath(t *testing.T) {
+	dir, cleanup := testTempDir(t)
+	defer cleanup()
+	todoPath := filepath.Join(dir, "todo.txt")
+	donePath := filepath.Join(dir, "done.txt")
+
+	if err := ioutil.WriteFile(todoPath, []byte("(A) Call Mom @Phone\nx 2014-01-02 Pick up milk\nPlan backyard herb garden\nx 2014-01-03 Outline chapter 5\nx 2014-01-03 Outline chapter 5\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(donePath, []byte("x 2014-01-01 Old task\nx 2014-01-02 Pick up milk"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := testTasklist.LoadFromPath(todoPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := testTasklist.ArchiveToPath(todoPath, donePath); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(todoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testExpected = "(A) Call Mom @Phone\nPlan backyard herb garden\n"
+	testGot = string(data)
+	if testGot != testExpected {
+		t.Errorf("Expected todo file to be [%s], but got [%s]", testExpected, testGot)
+	}
+
+	// completed tasks identical to older lines of done file are appended
+	data, err = ioutil.ReadFile(donePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testExpected = "x 2014-01-01 Old task\nx 2014-01-02 Pick up milk\nx 2014-01-02 Pick up milk\nx 2014-01-03 Outline chapter 5\nx 2014-01-03 Outline chapter 5\n"
+	testGot = string(data)
+	if testGot != testExpected {
+		t.Errorf("Expected done file to be [%s], but got [%s]", testExpected, testGot)
+	}
+
+	testExpected = 3
+	testGot = testTasklist[1].ID
+	if testGot != testExpected {
+		t.Errorf("Expected remaining Task to keep ID [%d], but got [%d]", testExpected, testGot)
+	}
+
+	// the same completed task archived again is appended again
+	if err := ioutil.WriteFile(todoPath, []byte("x 2024-05-01 Call Mom\nOpen\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		tasklist, err := LoadFromPath(todoPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := tasklist.ArchiveToPath(todoPath, donePath); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(todoPath, []byte("x 2024-05-01 Call Mom\nOpen\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err = ioutil.ReadFile(donePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testExpected = "x 2014-01-01 Old task\nx 2014-01-02 Pick up milk\nx 2014-01-02 Pick up milk\nx 2014-01-03 Outline chapter 5\nx 2014-01-03 Outline chapter 5\n" +
+		"x 2024-05-01 Call Mom\nx 2024-05-01 Call Mom\n"
+	testGot = string(data)
+	if testGot != testExpected {
+		t.Errorf("Expected done file to be [%s], but got [%s]", testExpected, testGot)
+	}
+
+	// archive again after failing to write todo file, the tasks appended before are not duplicated
+	tasklist, err := LoadFromPath(todoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tasklist.ArchiveToPath(filepath.Join(dir, "missing", "todo.txt"), donePath); err == nil {
+		t.Fatal("Expected ArchiveToPath to fail for invalid todo file path")
+	}
+	if _, err := os.Stat(donePath + archiveJournalSuffix); err != nil {
+		t.Errorf("Expected journal to be kept after failure, but got error: %v", err)
+	}
+	if tasklist, err = LoadFromPath(todoPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := tasklist.ArchiveToPath(todoPath, donePath); err != nil {
+		t.Fatal(err)
+	}
+	data, err = ioutil.ReadFile(donePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testExpected = "x 2014-01-01 Old task\nx 2014-01-02 Pick up milk\nx 2014-01-02 Pick up milk\nx 2014-01-03 Outline chapter 5\nx 2014-01-03 Outline chapter 5\n" +
+		"x 2024-05-01 Call Mom\nx 2024-05-01 Call Mom\nx 2024-05-01 Call Mom\n"
+	testGot = string(data)
+	if testGot != testExpected {
+		t.Errorf("Expected done file to be [%s], but got [%s]", testExpected, testGot)
+	}
+	if _, err := os.Stat(donePath + archiveJournalSuffix); !os.IsNotExist(err) {
+		t.Errorf("Expected journal to be removed, but got: %v", err)
+	}
+
+	// a partially appended done file is truncated before archiving again
+	if err := ioutil.WriteFile(donePath+archiveJournalSuffix, []byte("0\n"+strings.Repeat("0", 64)+"\nx 2024-05-01 Call Mom\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(donePath, []byte("x 2024-05-01 Ca"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(todoPath, []byte("x 2024-05-01 Call Mom\nOpen\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if tasklist, err = LoadFromPath(todoPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := tasklist.ArchiveToPath(todoPath, donePath); err != nil {
+		t.Fatal(err)
+	}
+	data, err = ioutil.ReadFile(donePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testExpected = "x 2024-05-01 Call Mom\n"
+	testGot = string(data)
+	if testGot != testExpected {
+		t.Errorf("Expected done file to be [%s], but got [%s]", testExpected, testGot)
+	}
+
+	if err := testTasklist.ArchiveToPath(todoPath, filepath.Join(dir, "missing", "done.txt")); err != nil {
+		t.Errorf("Expected nothing to archive, but got error: %v", err)
+	}
+	testTasklist[0].Complete()
+	if err := testTasklist.ArchiveToPath(todoPath, filepath.Join(dir, "missing", "done.txt")); err == nil {
+		t.Errorf("Expected ArchiveToPath to fail for invalid done file path")
+	}
+	testExpected = 2
+	testGot = len(testTasklist)
+	if testGot != testExpected {
+		t.Errorf("Expected TaskList not to be modified, but got %d tasks", testGot)
+	}
+}
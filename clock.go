@@ -0,0 +1,55 @@
+package todotxt
+
+import "time"
+
+// Clock provides the current time for time-relative methods of Task, e.g. IsOverdue() and IsDueToday().
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc is an adapter to allow the use of ordinary functions as Clock.
+type ClockFunc func() time.Time
+
+// Now returns f().
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// SystemClock is the Clock using time.Now(). It's used if no other Clock is set.
+var SystemClock Clock = ClockFunc(time.Now)
+
+// FixedClock returns a Clock that always returns the given time. It's useful for tests and reports of the past.
+func FixedClock(t time.Time) Clock {
+	return ClockFunc(func() time.Time {
+		return t
+	})
+}
+
+// SetClock sets the Clock used by time-relative methods of the task. If clock is nil, SystemClock is used.
+func (task *Task) SetClock(clock Clock) {
+	task.clock = clock
+}
+
+// now returns the current time of the task's Clock in the task's location.
+func (task *Task) now() time.Time {
+	if task.clock != nil {
+		return task.clock.Now().In(task.Location())
+	}
+	return SystemClock.Now().In(task.Location())
+}
+
+// now returns the current time of the parser's Clock in the parser's location.
+func (p *Parser) now() time.Time {
+	if p.opts.Clock != nil {
+		return p.opts.Clock.Now().In(p.location())
+	}
+	return SystemClock.Now().In(p.location())
+}
+
+// SetClock sets the Clock used by time-relative methods of all tasks in the TaskList. If clock is nil, SystemClock is used.
+// Tasks added later by AddTask() get the Clock too, unless the TaskList is empty.
+func (tasklist *TaskList) SetClock(clock Clock) {
+	for i := range *tasklist {
+		(*tasklist)[i].clock = clock
+	}
+}
@@ -0,0 +1,123 @@
+package todotxt
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFixedClock(t *testing.T) {
+	now := time.Date(2020, 2, 28, 23, 59, 59, 0, time.Local)
+	clock := FixedClock(now)
+	if got := clock.Now(); !got.Equal(now) {
+		t.Errorf("Expected clock to return [%v], but got [%v]", now, got)
+	}
+
+	if got := SystemClock.Now(); time.Since(got) > time.Minute {
+		t.Errorf("Expected system clock to return current time, but got [%v]", got)
+	}
+}
+
+func TestTaskClock(t *testing.T) {
+	task, err := ParseTask("Call Mom due:2020-02-29")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		now      time.Time
+		overdue  bool
+		dueToday bool
+		due      time.Duration
+	}{
+		{time.Date(2020, 2, 28, 12, 0, 0, 0, time.Local), false, false, 36 * time.Hour},
+		{time.Date(2020, 2, 29, 0, 0, 0, 0, time.Local), false, true, 24 * time.Hour},
+		{time.Date(2020, 2, 29, 23, 0, 0, 0, time.Local), false, true, time.Hour},
+		{time.Date(2020, 3, 1, 1, 0, 0, 0, time.Local), true, false, -time.Hour},
+	}
+	for _, c := range cases {
+		task.SetClock(FixedClock(c.now))
+		if got := task.IsOverdue(); got != c.overdue {
+			t.Errorf("Expected IsOverdue() at [%v] to be %v, but got %v", c.now, c.overdue, got)
+		}
+		if got := task.IsDueToday(); got != c.dueToday {
+			t.Errorf("Expected IsDueToday() at [%v] to be %v, but got %v", c.now, c.dueToday, got)
+		}
+		if got := task.Due(); got != c.due {
+			t.Errorf("Expected Due() at [%v] to be %v, but got %v", c.now, c.due, got)
+		}
+	}
+
+	now := time.Date(2020, 2, 28, 12, 0, 0, 0, time.Local)
+	task.SetClock(FixedClock(now))
+	task.Complete()
+	testExpected = "x 2020-02-28 Call Mom due:2020-02-29"
+	testGot = task.String()
+	if testGot != testExpected {
+		t.Errorf("Expected Task to be [%s], but got [%s]", testExpected, testGot)
+	}
+
+	task.SetClock(nil)
+	if task.IsOverdue() != (time.Now().After(time.Date(2020, 3, 1, 0, 0, 0, 0, time.Local))) {
+		t.Errorf("Expected Task to use system clock")
+	}
+}
+
+func TestTaskListClock(t *testing.T) {
+	if err := testTasklist.LoadFromPath(testInputFilter); err != nil {
+		t.Fatal(err)
+	}
+	before := len(testTasklist.Filter(FilterOverdue))
+
+	// nothing is overdue in the distant past
+	testTasklist.SetClock(FixedClock(time.Date(2000, 1, 1, 0, 0, 0, 0, time.Local)))
+	testExpected = 0
+	testGot = len(testTasklist.Filter(FilterOverdue))
+	if testGot != testExpected {
+		t.Errorf("Expected TaskList to contain %d overdue tasks, but got %d", testExpected, testGot)
+	}
+
+	// added tasks get the clock of the list
+	task, err := ParseTask("Pay rent due:2010-01-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testTasklist.AddTask(task)
+	if testTasklist[len(testTasklist)-1].IsOverdue() {
+		t.Errorf("Expected added Task to use the clock of TaskList")
+	}
+
+	testTasklist.SetClock(nil)
+	testExpected = before + 1
+	testGot = len(testTasklist.Filter(FilterOverdue))
+	if testGot != testExpected {
+		t.Errorf("Expected TaskList to contain %d overdue tasks, but got %d", testExpected, testGot)
+	}
+}
+
+func TestParserClock(t *testing.T) {
+	now := time.Date(2014, 1, 12, 8, 0, 0, 0, time.Local)
+	parser := NewParser(Options{Clock: FixedClock(now)})
+
+	task := parser.NewTask()
+	testExpected = "2014-01-12 "
+	testGot = parser.Format(&task)
+	if testGot != testExpected {
+		t.Errorf("Expected Task to be [%s], but got [%s]", testExpected, testGot)
+	}
+
+	tasklist, err := parser.Load(strings.NewReader("Call Mom due:2014-01-12\nCall Dad due:2014-01-11\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testExpected = "Call Mom"
+	testGot = tasklist.Filter(FilterDueToday)[0].Todo
+	if testGot != testExpected {
+		t.Errorf("Expected Task due today to be [%s], but got [%s]", testExpected, testGot)
+	}
+	testExpected = "Call Dad"
+	testGot = tasklist.Filter(FilterOverdue)[0].Todo
+	if testGot != testExpected {
+		t.Errorf("Expected overdue Task to be [%s], but got [%s]", testExpected, testGot)
+	}
+}
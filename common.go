@@ -19,5 +19,12 @@ func isNotEmpty(s string) bool {
 }
 
 func parseTime(s string) (time.Time, error) {
-	return time.ParseInLocation(DateLayout, s, time.Local)
+	return defaultParser().parseTime(s)
+}
+
+// daysBetween returns the number of calendar days from a to b, it's negative if b is before a.
+func daysBetween(a, b time.Time) int {
+	da := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	db := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(db.Sub(da) / oneDay)
 }
@@ -2,6 +2,8 @@ package todotxt
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
 	"strings"
 	"testing"
 )
@@ -15,6 +17,8 @@ var (
 	testInputTasklistDueDateError       = "testdata/tasklist_dueDate_error.txt"
 	testInputTasklistCompletedDateError = "testdata/tasklist_completedDate_error.txt"
 	testInputTasklistScannerError       = "testdata/tasklist_scanner_error.txt"
+	testInputTasklistComments           = "testdata/tasklist_comments.txt"
+	testInputTasklistLenient            = "testdata/tasklist_lenient.txt"
 	testOutput                          = "testdata/output_todo.txt"
 	testExpectedOutput                  = "testdata/expected_todo.txt"
 	testTasklist                        TaskList
@@ -28,6 +32,15 @@ var _ = func() interface{} {
 	return nil
 }()
 
+// testTempDir creates a temporary directory for testing, and returns it with the function for removing it.
+func testTempDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "todotxt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, func() { os.RemoveAll(dir) }
+}
+
 func compareSlices(list1 []string, list2 []string) bool {
 	if len(list1) != len(list2) {
 		return false
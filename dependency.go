@@ -0,0 +1,275 @@
+package todotxt
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Keys of additional tags for relations between tasks. The values are references to other tasks in the same TaskList,
+// either the unique identifier given by "id:" tag, or the numeric Task.ID.
+const (
+	DependencyTag = "dep" // Tasks which must be completed first, separated by commas, e.g. "dep:3,a1b2".
+	ParentTag     = "p"   // Parent task of a subtask, e.g. "p:3". The parent is blocked until all its subtasks are completed.
+)
+
+// DanglingRef represents a reference to a task which is not in the TaskList.
+type DanglingRef struct {
+	Task *Task  // Task with the reference.
+	Tag  string // Key of the tag with the reference, DependencyTag or ParentTag.
+	Ref  string // Reference to the missing task.
+}
+
+// DependencyGraph represents the blocking relations between tasks of a TaskList.
+type DependencyGraph struct {
+	Tasks    []*Task           // All tasks in topological order, i.e. each task comes after all tasks blocking it.
+	Blockers map[*Task][]*Task // Tasks blocking each task, i.e. its dependencies and subtasks, including completed ones.
+	Dangling []DanglingRef     // References to tasks which are not in the TaskList.
+}
+
+// Dependencies returns the references to tasks this task depends on, given by "dep:" tag.
+func (task *Task) Dependencies() []string {
+	var refs []string
+	for _, ref := range strings.Split(task.AdditionalTags[DependencyTag], ",") {
+		if isNotEmpty(ref) {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// HasDependencies returns true if the task depends on other tasks.
+func (task *Task) HasDependencies() bool {
+	return len(task.Dependencies()) > 0
+}
+
+// Parent returns the reference to the parent task of this subtask, given by "p:" tag, or empty string if it's not a subtask.
+func (task *Task) Parent() string {
+	return task.AdditionalTags[ParentTag]
+}
+
+// HasParent returns true if the task is a subtask of another task.
+func (task *Task) HasParent() bool {
+	return isNotEmpty(task.Parent())
+}
+
+// taskRefs resolves references to tasks of a TaskList.
+type taskRefs struct {
+	uids map[string]int // Index of task by unique identifier.
+	ids  map[int]int    // Index of task by Task.ID.
+}
+
+// newTaskRefs returns the taskRefs of the TaskList.
+func newTaskRefs(tasklist TaskList) *taskRefs {
+	refs := &taskRefs{uids: make(map[string]int), ids: make(map[int]int)}
+	for i := len(tasklist) - 1; i >= 0; i-- {
+		if t := &tasklist[i]; t.IsTask() {
+			if t.HasUID() {
+				refs.uids[t.UID()] = i
+			}
+			refs.ids[t.ID] = i
+		}
+	}
+	return refs
+}
+
+// resolve returns the index of the task with the given reference, or -1 if not found.
+// A reference is resolved by unique identifier first, and then by numeric Task.ID.
+func (r *taskRefs) resolve(ref string) int {
+	if i, found := r.uids[ref]; found {
+		return i
+	}
+	if id, err := strconv.Atoi(ref); err == nil {
+		if i, found := r.ids[id]; found {
+			return i
+		}
+	}
+	return -1
+}
+
+// isSameEntry returns true if the task is the i-th task of the TaskList, or a copy of it.
+func (tasklist TaskList) isSameEntry(i int, task *Task) bool {
+	return &tasklist[i] == task || (task.ID != 0 && tasklist[i].ID == task.ID)
+}
+
+// blockerIndexes returns the indexes of the tasks blocking the task, i.e. its dependencies and subtasks, and its dangling references.
+func (tasklist TaskList) blockerIndexes(refs *taskRefs, task *Task) ([]int, []DanglingRef) {
+	var (
+		indexes  []int
+		dangling []DanglingRef
+	)
+	seen := make(map[int]bool)
+	for _, ref := range task.Dependencies() {
+		if i := refs.resolve(ref); i < 0 {
+			dangling = append(dangling, DanglingRef{Task: task, Tag: DependencyTag, Ref: ref})
+		} else if !seen[i] {
+			seen[i] = true
+			indexes = append(indexes, i)
+		}
+	}
+	for i := range tasklist {
+		if child := &tasklist[i]; child.IsTask() && child.HasParent() && !seen[i] {
+			if p := refs.resolve(child.Parent()); p >= 0 && tasklist.isSameEntry(p, task) {
+				seen[i] = true
+				indexes = append(indexes, i)
+			}
+		}
+	}
+	if task.HasParent() && refs.resolve(task.Parent()) < 0 {
+		dangling = append(dangling, DanglingRef{Task: task, Tag: ParentTag, Ref: task.Parent()})
+	}
+	return indexes, dangling
+}
+
+// Blockers returns the tasks in the TaskList blocking the given task: its dependencies and its subtasks which are not completed yet.
+// The returned Task pointers can be used to update the Tasks inside the TaskList. References to missing tasks are ignored.
+func (tasklist *TaskList) Blockers(task *Task) []*Task {
+	var blockers []*Task
+	indexes, _ := tasklist.blockerIndexes(newTaskRefs(*tasklist), task)
+	for _, i := range indexes {
+		if t := &(*tasklist)[i]; !t.Completed {
+			blockers = append(blockers, t)
+		}
+	}
+	return blockers
+}
+
+// IsBlocked returns true if the given task is blocked by any dependency or subtask in the TaskList which is not completed yet.
+func (tasklist *TaskList) IsBlocked(task *Task) bool {
+	return len(tasklist.Blockers(task)) > 0
+}
+
+// FilterUnblocked returns a predicate which filters tasks not blocked by any dependency or subtask in the given TaskList.
+// The blocked tasks are determined when the predicate is created, and identified by unique identifier given by "id:" tag,
+// or by Task.ID, or by the task text for tasks without Task.ID, e.g. tasks not added by TaskList.AddTask().
+func FilterUnblocked(tasklist TaskList) Predicate {
+	var (
+		uids  = make(map[string]bool)
+		ids   = make(map[int]bool)
+		texts = make(map[string]bool)
+	)
+	isBlocked, _ := tasklist.blockingStatus()
+	for i := range tasklist {
+		if t := &tasklist[i]; isBlocked[i] {
+			switch {
+			case t.HasUID():
+				uids[t.UID()] = true
+			case t.ID != 0:
+				ids[t.ID] = true
+			default:
+				texts[t.String()] = true
+			}
+		}
+	}
+	return func(t Task) bool {
+		switch {
+		case t.HasUID():
+			return !uids[t.UID()]
+		case t.ID != 0:
+			return !ids[t.ID]
+		default:
+			return !texts[t.String()]
+		}
+	}
+}
+
+// blockingStatus returns for each entry of the TaskList, whether the task is blocked by any task not completed yet,
+// and whether the task is not completed yet and blocks any task not completed yet.
+func (tasklist TaskList) blockingStatus() (blocked, blocking []bool) {
+	blocked, blocking = make([]bool, len(tasklist)), make([]bool, len(tasklist))
+	refs := newTaskRefs(tasklist)
+	for i := range tasklist {
+		if t := &tasklist[i]; t.IsTask() {
+			indexes, _ := tasklist.blockerIndexes(refs, t)
+			for _, j := range indexes {
+				if !tasklist[j].Completed {
+					blocked[i] = true
+					blocking[j] = blocking[j] || !t.Completed
+				}
+			}
+		}
+	}
+	return blocked, blocking
+}
+
+// Graph returns the dependency graph of all tasks in the TaskList, with tasks in topological order and dangling references.
+// The returned Task pointers can be used to update the Tasks inside the TaskList.
+// Returns a *CycleError if tasks block each other, directly or indirectly.
+func (tasklist *TaskList) Graph() (*DependencyGraph, error) {
+	list := *tasklist
+	graph := &DependencyGraph{Blockers: make(map[*Task][]*Task)}
+	blockers := make([][]int, len(list))
+	refs := newTaskRefs(list)
+	for i := range list {
+		if task := &list[i]; task.IsTask() {
+			var dangling []DanglingRef
+			blockers[i], dangling = list.blockerIndexes(refs, task)
+			graph.Dangling = append(graph.Dangling, dangling...)
+			for _, j := range blockers[i] {
+				graph.Blockers[task] = append(graph.Blockers[task], &list[j])
+			}
+		}
+	}
+
+	// depth-first search in the order of the TaskList, adding each task after its blockers
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(list))
+	var path []int
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case visited:
+			return nil
+		case visiting:
+			cycle := &CycleError{}
+			for k := len(path) - 1; k >= 0; k-- {
+				if path[k] == i {
+					for _, j := range path[k:] {
+						cycle.Tasks = append(cycle.Tasks, &list[j])
+					}
+					break
+				}
+			}
+			return cycle
+		}
+		state[i] = visiting
+		path = append(path, i)
+		for _, j := range blockers[i] {
+			if err := visit(j); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[i] = visited
+		graph.Tasks = append(graph.Tasks, &list[i])
+		return nil
+	}
+	for i := range list {
+		if list[i].IsTask() {
+			if err := visit(i); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return graph, nil
+}
+
+// CycleError is returned by TaskList.Graph() if tasks block each other.
+type CycleError struct {
+	Tasks []*Task // Tasks in the cycle, each task is blocked by the next one, and the last one is blocked by the first one.
+}
+
+// Error returns the error message with the IDs of the tasks in the cycle.
+func (e *CycleError) Error() string {
+	ids := make([]string, 0, len(e.Tasks)+1)
+	for _, t := range e.Tasks {
+		ids = append(ids, strconv.Itoa(t.ID))
+	}
+	if len(e.Tasks) > 0 {
+		ids = append(ids, ids[0])
+	}
+	return "dependency cycle of tasks: " + strings.Join(ids, " -> ")
+}
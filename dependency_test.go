@@ -0,0 +1,149 @@
+package todotxt
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTaskDependencies(t *testing.T) {
+	task, _ := ParseTask("Deploy dep:3,a1,,3 p:x9")
+	testExpected = "[3 a1 3]"
+	testGot = fmt.Sprint(task.Dependencies())
+	if testGot != testExpected {
+		t.Errorf("Expected Task dependencies to be %s, but got %s", testExpected, testGot)
+	}
+	testExpected = "x9"
+	testGot = task.Parent()
+	if testGot != testExpected {
+		t.Errorf("Expected Task parent to be %s, but got %s", testExpected, testGot)
+	}
+	if !task.HasDependencies() || !task.HasParent() {
+		t.Errorf("Expected Task to have dependencies and parent")
+	}
+
+	task, _ = ParseTask("Deploy")
+	if task.HasDependencies() || task.HasParent() {
+		t.Errorf("Expected Task to have no dependencies and parent")
+	}
+}
+
+func TestTaskListBlockers(t *testing.T) {
+	tasklist, err := LoadFromReader(strings.NewReader(`Release id:rel dep:2,build
+Write code id:build
+x Write docs
+Write changelog p:rel
+x Update version p:1
+Test release dep:99 p:nope
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testExpected = "[Write code id:build Write changelog p:rel]"
+	testGot = fmt.Sprint(tasklist.Blockers(&tasklist[0]))
+	if testGot != testExpected {
+		t.Errorf("Expected blockers to be %s, but got %s", testExpected, testGot)
+	}
+	for i, blocked := range []bool{true, false, false, false, false, false} {
+		testExpected = blocked
+		testGot = tasklist.IsBlocked(&tasklist[i])
+		if testGot != testExpected {
+			t.Errorf("(%d) Expected IsBlocked to be %v, but got %v", i, testExpected, testGot)
+		}
+	}
+
+	// copies of tasks are blocked, too
+	task := tasklist[0]
+	if !tasklist.IsBlocked(&task) {
+		t.Errorf("Expected copy of task to be blocked")
+	}
+	testExpected = "Write code id:build\nx Write docs\nWrite changelog p:rel\nx Update version p:1\nTest release dep:99 p:nope\n"
+	testGot = tasklist.Filter(FilterUnblocked(tasklist)).String()
+	if testGot != testExpected {
+		t.Errorf("Expected unblocked tasks to be [%s], but got [%s]", testExpected, testGot)
+	}
+
+	// tasks without ID are identified by text
+	var unnumbered TaskList
+	for _, text := range []string{"Write code id:build", "Release dep:build", "Write docs", "Publish p:2"} {
+		task, err := ParseTask(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		unnumbered = append(unnumbered, *task)
+	}
+	testExpected = "Write code id:build\nWrite docs\nPublish p:2\n"
+	testGot = unnumbered.Filter(FilterUnblocked(unnumbered)).String()
+	if testGot != testExpected {
+		t.Errorf("Expected unblocked tasks to be [%s], but got [%s]", testExpected, testGot)
+	}
+
+	// blockers are removed after completion
+	tasklist[1].Complete()
+	tasklist[3].Complete()
+	if tasklist.IsBlocked(&tasklist[0]) {
+		t.Errorf("Expected task not to be blocked after completion of blockers")
+	}
+}
+
+func TestTaskListGraph(t *testing.T) {
+	tasklist, err := LoadFromReader(strings.NewReader(`Release id:rel dep:2,build
+Write code id:build dep:3
+Write design
+Write changelog p:rel
+Test release dep:99 p:nope
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	graph, err := tasklist.Graph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int
+	for _, task := range graph.Tasks {
+		ids = append(ids, task.ID)
+	}
+	testExpected = "[3 2 4 1 5]"
+	testGot = fmt.Sprint(ids)
+	if testGot != testExpected {
+		t.Errorf("Expected topological order to be %s, but got %s", testExpected, testGot)
+	}
+	testExpected = "[Write code dep:3 id:build Write changelog p:rel]"
+	testGot = fmt.Sprint(graph.Blockers[&tasklist[0]])
+	if testGot != testExpected {
+		t.Errorf("Expected blockers to be %s, but got %s", testExpected, testGot)
+	}
+	testExpected = 2
+	testGot = len(graph.Dangling)
+	if testGot != testExpected {
+		t.Fatalf("Expected %d dangling references, but got %d", testExpected, testGot)
+	}
+	testExpected = "5 dep 99, 5 p nope"
+	testGot = fmt.Sprintf("%d %s %s, %d %s %s", graph.Dangling[0].Task.ID, graph.Dangling[0].Tag, graph.Dangling[0].Ref,
+		graph.Dangling[1].Task.ID, graph.Dangling[1].Tag, graph.Dangling[1].Ref)
+	if testGot != testExpected {
+		t.Errorf("Expected dangling references to be [%s], but got [%s]", testExpected, testGot)
+	}
+
+	// cycles
+	tasklist[2].AdditionalTags = map[string]string{DependencyTag: "rel"}
+	_, err = tasklist.Graph()
+	var cerr *CycleError
+	if !errors.As(err, &cerr) {
+		t.Fatalf("Expected *CycleError, but got: %v", err)
+	}
+	testExpected = "dependency cycle of tasks: 1 -> 2 -> 3 -> 1"
+	testGot = cerr.Error()
+	if testGot != testExpected {
+		t.Errorf("Expected error to be [%s], but got [%s]", testExpected, testGot)
+	}
+
+	tasklist = TaskList{{ID: 1, Todo: "Self", AdditionalTags: map[string]string{ParentTag: "1"}}}
+	if _, err = tasklist.Graph(); err == nil || err.Error() != "dependency cycle of tasks: 1 -> 1" {
+		t.Errorf("Expected self-reference to be a cycle, but got: %v", err)
+	}
+}
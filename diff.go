@@ -0,0 +1,123 @@
+package todotxt
+
+// ChangeType represents type of change of a task between two TaskLists.
+//go:generate stringer -type ChangeType -trimprefix Change -output change_type.go
+type ChangeType uint8
+
+// Flags for indicating type of change of a task.
+const (
+	ChangeAdded         ChangeType = iota + 1 // Task was added.
+	ChangeRemoved                             // Task was removed.
+	ChangeCompleted                           // Task was completed.
+	ChangeReopened                            // Completed task was reopened.
+	ChangeReprioritized                       // Priority was set, changed or removed.
+	ChangeRetagged                            // Contexts, projects or additional tags were added, changed or removed.
+	ChangeModified                            // Todo text, created, completed, due or threshold date was changed.
+)
+
+// FieldChange represents a changed field of a task, with the values before and after the change.
+// Each added or removed context and project is a separate FieldChange.
+type FieldChange struct {
+	Field  TaskSegmentType // Changed field.
+	Tag    string          // Key of the changed additional tag, if Field is SegmentTag.
+	Before string          // Value of the field before the change, empty if not set.
+	After  string          // Value of the field after the change, empty if not set.
+}
+
+// Change represents a change of a task between two TaskLists.
+type Change struct {
+	Type   ChangeType    // Type of the change.
+	Old    *Task         // Task in the old TaskList, nil for ChangeAdded.
+	New    *Task         // Task in the new TaskList, nil for ChangeRemoved.
+	Fields []FieldChange // Changed fields of the task, empty for ChangeAdded and ChangeRemoved.
+}
+
+// Diff returns the semantic changes of tasks from the old TaskList to the new TaskList.
+//
+// Tasks are matched by identity just like Merge(), rather than by position or String() representation.
+// A task with changes of several types, e.g. completed and retagged, gets a Change for each type, in the order of ChangeType.
+// Changes are ordered by the tasks in the new TaskList, followed by the removed tasks in the order of the old TaskList.
+// Comment lines are ignored, and the Old and New tasks point into the given TaskLists.
+func Diff(old, new TaskList) []Change {
+	var changes []Change
+	oldMatch := reverseMatch(matchTasks(old, new), len(new))
+	matched := make([]bool, len(old))
+	for j := range new {
+		task := &new[j]
+		if !task.IsTask() {
+			continue
+		}
+		if i := oldMatch[j]; i >= 0 {
+			matched[i] = true
+			changes = append(changes, diffTask(&old[i], task)...)
+		} else {
+			changes = append(changes, Change{Type: ChangeAdded, New: task})
+		}
+	}
+	for i := range old {
+		if old[i].IsTask() && !matched[i] {
+			changes = append(changes, Change{Type: ChangeRemoved, Old: &old[i]})
+		}
+	}
+	return changes
+}
+
+// diffTask returns the changes from the old task to the new task.
+func diffTask(old, new *Task) []Change {
+	fields := make(map[ChangeType][]FieldChange)
+	add := func(t ChangeType, field TaskSegmentType, tag, before, after string) {
+		if before != after {
+			fields[t] = append(fields[t], FieldChange{Field: field, Tag: tag, Before: before, After: after})
+		}
+	}
+
+	switch {
+	case !old.Completed && new.Completed:
+		add(ChangeCompleted, SegmentIsCompleted, emptyStr, completedValue(old), completedValue(new))
+	case old.Completed && !new.Completed:
+		add(ChangeReopened, SegmentIsCompleted, emptyStr, completedValue(old), completedValue(new))
+	case old.Completed && new.Completed:
+		add(ChangeModified, SegmentCompletedDate, emptyStr, completedDateValue(old), completedDateValue(new))
+	}
+	add(ChangeReprioritized, SegmentPriority, emptyStr, old.Priority, new.Priority)
+	add(ChangeModified, SegmentCreatedDate, emptyStr, dateValue(old.CreatedDate), dateValue(new.CreatedDate))
+	add(ChangeModified, SegmentTodoText, emptyStr, old.Todo, new.Todo)
+
+	diffSet := func(field TaskSegmentType, before, after []string) {
+		inBefore, inAfter := stringSet(before), stringSet(after)
+		for _, s := range sortedStrings(before) {
+			if !inAfter[s] {
+				add(ChangeRetagged, field, emptyStr, s, emptyStr)
+			}
+		}
+		for _, s := range sortedStrings(after) {
+			if !inBefore[s] {
+				add(ChangeRetagged, field, emptyStr, emptyStr, s)
+			}
+		}
+	}
+	diffSet(SegmentContext, old.Contexts, new.Contexts)
+	diffSet(SegmentProject, old.Projects, new.Projects)
+
+	keys := make(map[string]bool)
+	for key := range old.AdditionalTags {
+		keys[key] = true
+	}
+	for key := range new.AdditionalTags {
+		keys[key] = true
+	}
+	for _, key := range sortedStrings(stringKeys(keys)) {
+		add(ChangeRetagged, SegmentTag, key, old.AdditionalTags[key], new.AdditionalTags[key])
+	}
+
+	add(ChangeModified, SegmentDueDate, emptyStr, dueDateValue(old), dueDateValue(new))
+	add(ChangeModified, SegmentThresholdDate, emptyStr, dateValue(old.ThresholdDate), dateValue(new.ThresholdDate))
+
+	var changes []Change
+	for t := ChangeCompleted; t <= ChangeModified; t++ {
+		if len(fields[t]) > 0 {
+			changes = append(changes, Change{Type: t, Old: old, New: new, Fields: fields[t]})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,107 @@
+package todotxt
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	load := func(s string) TaskList {
+		tasklist, err := LoadFromReader(strings.NewReader(s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tasklist
+	}
+	old := load(`(A) 2020-01-01 Call Mom @phone
+x 2020-01-05 Buy milk +Groceries
+Pay rent due:2020-02-01
+Write report id:r1 owner:alice
+Clean garage
+`)
+	new := load(`Read book
+2020-01-01 Call Mom @home
+Buy milk +Groceries
+(B) Pay the rent due:2020-02-05
+x 2020-01-10 Write the final report id:r1 owner:bob @work
+`)
+
+	changes := Diff(old, new)
+	expected := []string{
+		"Added <nil> -> Read book []",
+		"Reprioritized (A) 2020-01-01 Call Mom @phone -> 2020-01-01 Call Mom @home [{Priority  A }]",
+		"Retagged (A) 2020-01-01 Call Mom @phone -> 2020-01-01 Call Mom @home [{Context  phone } {Context   home}]",
+		"Reopened x 2020-01-05 Buy milk +Groceries -> Buy milk +Groceries [{IsCompleted  x 2020-01-05 }]",
+		"Reprioritized Pay rent due:2020-02-01 -> (B) Pay the rent due:2020-02-05 [{Priority   B}]",
+		"Modified Pay rent due:2020-02-01 -> (B) Pay the rent due:2020-02-05 [{TodoText  Pay rent Pay the rent} {DueDate  2020-02-01 2020-02-05}]",
+		"Completed Write report id:r1 owner:alice -> x 2020-01-10 Write the final report @work id:r1 owner:bob [{IsCompleted   x 2020-01-10}]",
+		"Retagged Write report id:r1 owner:alice -> x 2020-01-10 Write the final report @work id:r1 owner:bob [{Context   work} {Tag owner alice bob}]",
+		"Modified Write report id:r1 owner:alice -> x 2020-01-10 Write the final report @work id:r1 owner:bob [{TodoText  Write report Write the final report}]",
+		"Removed Clean garage -> <nil> []",
+	}
+	testExpected = len(expected)
+	testGot = len(changes)
+	if testGot != testExpected {
+		t.Errorf("Expected %d changes, but got %d", testExpected, testGot)
+	}
+	for i, c := range changes {
+		old, new := "<nil>", "<nil>"
+		if c.Old != nil {
+			old = c.Old.String()
+		}
+		if c.New != nil {
+			new = c.New.String()
+		}
+		testGot = fmt.Sprintf("%s %s -> %s %v", c.Type, old, new, c.Fields)
+		if i < len(expected) {
+			testExpected = expected[i]
+			if testGot != testExpected {
+				t.Errorf("(%d) Expected change to be [%s], but got [%s]", i, testExpected, testGot)
+			}
+		}
+	}
+
+	// completed date changed
+	changes = Diff(load("x 2020-01-05 Buy milk\n"), load("x 2020-01-06 Buy milk\n"))
+	testExpected = "[{Modified [{CompletedDate  2020-01-05 2020-01-06}]}]"
+	testGot = fmt.Sprint(changeSummary(changes))
+	if testGot != testExpected {
+		t.Errorf("Expected changes to be %s, but got %s", testExpected, testGot)
+	}
+
+	// no changes, comments are ignored
+	IgnoreComments, PreserveComments = true, true
+	defer func() { PreserveComments = false }()
+	if changes = Diff(old, load("# comment\n"+old.String())); len(changes) != 0 {
+		t.Errorf("Expected no changes, but got %v", changes)
+	}
+	if changes = Diff(nil, nil); len(changes) != 0 {
+		t.Errorf("Expected no changes, but got %v", changes)
+	}
+}
+
+func TestChangeTypeString(t *testing.T) {
+	testExpected = "Reprioritized"
+	testGot = ChangeReprioritized.String()
+	if testGot != testExpected {
+		t.Errorf("Expected ChangeType to be %s, but got %s", testExpected, testGot)
+	}
+	testExpected = "ChangeType(8)"
+	testGot = ChangeType(8).String()
+	if testGot != testExpected {
+		t.Errorf("Expected ChangeType to be %s, but got %s", testExpected, testGot)
+	}
+}
+
+// changeSummary returns the types and fields of changes for testing.
+func changeSummary(changes []Change) []interface{} {
+	var summary []interface{}
+	for _, c := range changes {
+		summary = append(summary, struct {
+			Type   ChangeType
+			Fields []FieldChange
+		}{c.Type, c.Fields})
+	}
+	return summary
+}
@@ -0,0 +1,83 @@
+package todotxt
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ParseError represents an error that occurred while parsing a task, with the position of the offending token.
+// The underlying error (usually a *time.ParseError) is available via errors.Unwrap().
+type ParseError struct {
+	Line   int             // Line number of the task in todo.txt file, starting from 1. It's 0 if the task is not parsed from a file.
+	Column int             // Byte column of the offending token in the line, starting from 1.
+	Text   string          // Raw text of the line.
+	Token  string          // Offending token, e.g. "2020-13-45".
+	Field  TaskSegmentType // Field that failed to parse, e.g. SegmentDueDate.
+	Err    error           // Underlying error.
+}
+
+// Error returns the error message with line number, column and field.
+func (e *ParseError) Error() string {
+	msg := fmt.Sprintf("column %d: invalid %s: %v", e.Column, fieldName(e.Field), e.Err)
+	if e.Line > 0 {
+		msg = fmt.Sprintf("line %d, %s", e.Line, msg)
+	}
+	return msg
+}
+
+// Unwrap returns the underlying error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// fieldName returns a readable name of the field for the given segment type.
+func fieldName(field TaskSegmentType) string {
+	switch field {
+	case SegmentCompletedDate:
+		return "completed date"
+	case SegmentCreatedDate:
+		return "created date"
+	case SegmentDueDate:
+		return "due date"
+	case SegmentThresholdDate:
+		return "threshold date"
+	default:
+		return field.String()
+	}
+}
+
+// ParseErrors is a list of ParseError collected while loading a TaskList with LenientLoading.
+type ParseErrors []*ParseError
+
+// Error returns the error messages of all parse errors.
+func (e ParseErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	if len(msgs) == 1 {
+		return msgs[0]
+	}
+	return fmt.Sprintf("%d parse errors: %s", len(msgs), strings.Join(msgs, "; "))
+}
+
+// Is returns true if any of the parse errors matches the target, so errors.Is() can check all of them.
+func (e ParseErrors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first parse error that matches the target and sets the target to it, so errors.As() can check all of them.
+func (e ParseErrors) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
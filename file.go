@@ -0,0 +1,258 @@
+package todotxt
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrConflict is the error matched by errors.Is() for a ConflictError.
+var ErrConflict = errors.New("file has been modified since it was loaded")
+
+// ConflictError is returned by SaveIfUnchanged() if the file on disk has been changed since it was loaded.
+type ConflictError struct {
+	Filename string      // Name of the file.
+	Expected Fingerprint // Fingerprint of the file when it was loaded.
+	Actual   Fingerprint // Fingerprint of the file on disk.
+}
+
+// Error returns the error message with the file name.
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Filename, ErrConflict)
+}
+
+// Is returns true if the target is ErrConflict.
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// Fingerprint identifies the content of a file at the time it was loaded or saved.
+// The zero value represents a file that doesn't exist.
+type Fingerprint struct {
+	ModTime time.Time         // Modification time of the file.
+	Size    int64             // Size of the file in bytes.
+	Hash    [sha256.Size]byte // SHA-256 hash of the file content.
+}
+
+// newFingerprint returns the Fingerprint of the file content and its modification time.
+func newFingerprint(data []byte, modTime time.Time) Fingerprint {
+	return Fingerprint{
+		ModTime: modTime,
+		Size:    int64(len(data)),
+		Hash:    sha256.Sum256(data),
+	}
+}
+
+// Equal returns true if both fingerprints are of the same file content. The modification time is not compared,
+// as it may change without changing the content, e.g. by touching the file.
+func (f Fingerprint) Equal(other Fingerprint) bool {
+	return f.Size == other.Size && f.Hash == other.Hash
+}
+
+// IsZero returns true if the fingerprint represents a file that doesn't exist.
+func (f Fingerprint) IsZero() bool {
+	return f == Fingerprint{}
+}
+
+// FingerprintPath returns the Fingerprint of the file. If the file doesn't exist, the zero Fingerprint is returned without error.
+func FingerprintPath(filename string) (Fingerprint, error) {
+	data, modTime, err := readFile(filename)
+	if os.IsNotExist(err) {
+		return Fingerprint{}, nil
+	} else if err != nil {
+		return Fingerprint{}, err
+	}
+	return newFingerprint(data, modTime), nil
+}
+
+// readFile reads the whole file and returns its content and modification time.
+func readFile(filename string) ([]byte, time.Time, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return data, info.ModTime(), nil
+}
+
+// LoadFromPathWithFingerprint loads a TaskList from a file (most likely called "todo.txt") just like LoadFromPath(),
+// and returns the Fingerprint of the loaded content for SaveIfUnchanged().
+func (tasklist *TaskList) LoadFromPathWithFingerprint(filename string) (Fingerprint, error) {
+	return defaultParser().loadPathWithFingerprint(tasklist, filename)
+}
+
+// LoadFromPathWithFingerprint loads and returns a TaskList from a file (most likely called "todo.txt") with options of the parser,
+// with the Fingerprint of the loaded content. See TaskList.LoadFromPathWithFingerprint() for further information.
+func (p *Parser) LoadFromPathWithFingerprint(filename string) (TaskList, Fingerprint, error) {
+	tasklist := TaskList{}
+	fp, err := p.loadPathWithFingerprint(&tasklist, filename)
+	if err != nil {
+		tasklist, err = failedTaskList(tasklist, err)
+	}
+	return tasklist, fp, err
+}
+
+// loadPathWithFingerprint clears the given TaskList and loads tasks from the file into it, and returns the Fingerprint of the loaded content.
+func (p *Parser) loadPathWithFingerprint(tasklist *TaskList, filename string) (Fingerprint, error) {
+	data, modTime, err := readFile(filename)
+	if err != nil {
+		return Fingerprint{}, err
+	}
+	fp := newFingerprint(data, modTime)
+	return fp, p.loadInto(tasklist, bytes.NewReader(data))
+}
+
+// SaveIfUnchanged writes a TaskList to the file (most likely called "todo.txt") with WriteToPath(), only if the file content on disk still matches the Fingerprint,
+// i.e. it has not been modified by others since it was loaded by LoadFromPathWithFingerprint() or saved by SaveIfUnchanged().
+// Use the zero Fingerprint to save a new file only if it still doesn't exist.
+//
+// Returns a *ConflictError matching ErrConflict if the file has been changed, so the caller can reload and merge the changes.
+// Otherwise, returns the Fingerprint of the saved file for the next save.
+// The same advisory lock as WithLockedPath() is held while checking and writing the file.
+func (tasklist *TaskList) SaveIfUnchanged(filename string, fp Fingerprint) (Fingerprint, error) {
+	return defaultParser().SaveIfUnchanged(filename, *tasklist, fp)
+}
+
+// SaveIfUnchanged writes a TaskList to the file (most likely called "todo.txt") with options of the parser, only if the file content on disk still matches the Fingerprint.
+// See TaskList.SaveIfUnchanged() for further information.
+func (p *Parser) SaveIfUnchanged(filename string, tasklist TaskList, fp Fingerprint) (Fingerprint, error) {
+	unlock, err := lockPath(filename)
+	if err != nil {
+		return Fingerprint{}, err
+	}
+	defer unlock()
+
+	actual, err := FingerprintPath(filename)
+	if err != nil {
+		return Fingerprint{}, err
+	}
+	if !actual.Equal(fp) {
+		return Fingerprint{}, &ConflictError{Filename: filename, Expected: fp, Actual: actual}
+	}
+	if err := p.WriteToPath(filename, tasklist); err != nil {
+		return Fingerprint{}, err
+	}
+	return FingerprintPath(filename)
+}
+
+// LoadFromPathWithFingerprint loads and returns a TaskList from a file (most likely called "todo.txt"), with the Fingerprint of the loaded content.
+// See TaskList.LoadFromPathWithFingerprint() for further information.
+func LoadFromPathWithFingerprint(filename string) (TaskList, Fingerprint, error) {
+	tasklist := TaskList{}
+	fp, err := tasklist.LoadFromPathWithFingerprint(filename)
+	if err != nil {
+		tasklist, err = failedTaskList(tasklist, err)
+	}
+	return tasklist, fp, err
+}
+
+// writeFileAtomic writes data to a temporary file in the same directory, syncs it and renames it to the file.
+// If the file exists, its mode is kept, otherwise the file is created with mode 0640. Symbolic links are resolved, so the target file is replaced.
+func writeFileAtomic(filename string, data []byte) (err error) {
+	mode := os.FileMode(0640)
+	if target, err := filepath.EvalSymlinks(filename); err == nil {
+		filename = target
+	}
+	if info, err := os.Stat(filename); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	dir, base := filepath.Split(filename)
+	if isEmpty(dir) {
+		dir = "."
+	}
+	file, err := ioutil.TempFile(dir, "."+base+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = file.Close()
+			_ = os.Remove(file.Name())
+		}
+	}()
+
+	if _, err = file.Write(data); err != nil {
+		return err
+	}
+	if err = file.Sync(); err != nil {
+		return err
+	}
+	if err = file.Chmod(mode); err != nil {
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(file.Name(), filename); err != nil {
+		return err
+	}
+	syncDir(dir)
+	return nil
+}
+
+// syncDir syncs the directory to persist a rename on disk, errors are ignored as it's not supported on all platforms.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		_ = d.Sync()
+		_ = d.Close()
+	}
+}
+
+// WithLockedPath loads a TaskList from the file (most likely called "todo.txt"), calls fn with it, and writes it back to the file if fn returns no error.
+// An exclusive advisory lock is held on the lock file (the filename with ".lock" suffix) during the whole load-modify-save cycle,
+// so processes using WithLockedPath on the same file don't clobber each other's changes.
+//
+// If the file doesn't exist, fn is called with an empty TaskList and the file is created.
+// The file is written with WriteToPath(), so it's replaced atomically.
+func (tasklist *TaskList) WithLockedPath(filename string, fn func(*TaskList) error) error {
+	return defaultParser().withLockedPath(tasklist, filename, fn)
+}
+
+// WithLockedPath loads a TaskList from the file (most likely called "todo.txt") with options of the parser, calls fn with it,
+// and writes it back to the file with options of the parser if fn returns no error. See TaskList.WithLockedPath() for further information.
+func (p *Parser) WithLockedPath(filename string, fn func(*TaskList) error) error {
+	tasklist := TaskList{}
+	return p.withLockedPath(&tasklist, filename, fn)
+}
+
+// withLockedPath loads the file into the given TaskList, calls fn with it, and writes it back while holding the lock of the file.
+func (p *Parser) withLockedPath(tasklist *TaskList, filename string, fn func(*TaskList) error) error {
+	unlock, err := lockPath(filename)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := p.loadPathInto(tasklist, filename); err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+		*tasklist = TaskList{}
+	}
+	if err := fn(tasklist); err != nil {
+		return err
+	}
+	return p.WriteToPath(filename, *tasklist)
+}
+
+// WithLockedPath loads a TaskList from the file (most likely called "todo.txt"), calls fn with it, and writes it back to the file if fn returns no error.
+// See TaskList.WithLockedPath() for further information.
+func WithLockedPath(filename string, fn func(*TaskList) error) error {
+	tasklist := TaskList{}
+	return tasklist.WithLockedPath(filename, fn)
+}
@@ -0,0 +1,321 @@
+package todotxt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWriteToPathAtomic(t *testing.T) {
+	dir, cleanup := testTempDir(t)
+	defer cleanup()
+	filename := filepath.Join(dir, "todo.txt")
+
+	if err := testTasklist.LoadFromPath(testInputTasklist); err != nil {
+		t.Fatal(err)
+	}
+
+	// new file
+	if err := testTasklist.WriteToPath(filename); err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testExpected = os.FileMode(0640)
+		testGot = info.Mode().Perm()
+		if testGot != testExpected {
+			t.Errorf("Expected new file to have mode %v, but got %v", testExpected, testGot)
+		}
+
+		// existing file keeps its mode
+		if err := os.Chmod(filename, 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := testTasklist.WriteToPath(filename); err != nil {
+			t.Fatal(err)
+		}
+		if info, err = os.Stat(filename); err != nil {
+			t.Fatal(err)
+		}
+		testExpected = os.FileMode(0600)
+		testGot = info.Mode().Perm()
+		if testGot != testExpected {
+			t.Errorf("Expected existing file to keep mode %v, but got %v", testExpected, testGot)
+		}
+
+		// symbolic link is kept and its target is replaced
+		link := filepath.Join(dir, "link.txt")
+		if err := os.Symlink(filename, link); err != nil {
+			t.Fatal(err)
+		}
+		testTasklist = testTasklist[:1]
+		if err := testTasklist.WriteToPath(link); err != nil {
+			t.Fatal(err)
+		}
+		if info, err = os.Lstat(link); err != nil {
+			t.Fatal(err)
+		} else if info.Mode()&os.ModeSymlink == 0 {
+			t.Errorf("Expected symbolic link to be kept")
+		}
+		data, err := ioutil.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testExpected = testTasklist.String()
+		testGot = string(data)
+		if testGot != testExpected {
+			t.Errorf("Expected link target to be [%s], but got [%s]", testExpected, testGot)
+		}
+	}
+
+	// no temporary files are left behind
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		if f.Name() != "todo.txt" && f.Name() != "link.txt" {
+			t.Errorf("Expected no temporary file, but got %s", f.Name())
+		}
+	}
+
+	if err := testTasklist.WriteToPath(filepath.Join(dir, "missing", "todo.txt")); err == nil {
+		t.Errorf("Expected WriteToPath to fail for missing directory")
+	}
+}
+
+func TestWithLockedPath(t *testing.T) {
+	dir, cleanup := testTempDir(t)
+	defer cleanup()
+	filename := filepath.Join(dir, "todo.txt")
+
+	// concurrent load-modify-save cycles on a missing file
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := WithLockedPath(filename, func(tasklist *TaskList) error {
+				task, err := ParseTask(fmt.Sprintf("Task %d", i))
+				if err != nil {
+					return err
+				}
+				tasklist.AddTask(task)
+				return nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if err := testTasklist.LoadFromPath(filename); err != nil {
+		t.Fatal(err)
+	}
+	testExpected = 20
+	testGot = len(testTasklist)
+	if testGot != testExpected {
+		t.Errorf("Expected TaskList to contain %d tasks, but got %d", testExpected, testGot)
+	}
+
+	// nothing is written if fn fails
+	errStop := errors.New("stop")
+	err := testTasklist.WithLockedPath(filename, func(tasklist *TaskList) error {
+		*tasklist = TaskList{}
+		return errStop
+	})
+	if err != errStop {
+		t.Errorf("Expected WithLockedPath to return error of fn, but got: %v", err)
+	}
+	if err := testTasklist.LoadFromPath(filename); err != nil {
+		t.Fatal(err)
+	}
+	testExpected = 20
+	testGot = len(testTasklist)
+	if testGot != testExpected {
+		t.Errorf("Expected TaskList to contain %d tasks, but got %d", testExpected, testGot)
+	}
+
+	// lock can be acquired again after release
+	if err := WithLockedPath(filename, func(*TaskList) error { return nil }); err != nil {
+		t.Error(err)
+	}
+
+	if err := WithLockedPath(filepath.Join(dir, "missing", "todo.txt"), func(*TaskList) error { return nil }); err == nil {
+		t.Errorf("Expected WithLockedPath to fail for missing directory")
+	}
+	if err := WithLockedPath(testInputTasklistDueDateError, func(*TaskList) error { return nil }); err == nil {
+		t.Errorf("Expected WithLockedPath to fail for invalid file")
+	}
+	_ = os.Remove(testInputTasklistDueDateError + ".lock")
+}
+
+func TestSaveIfUnchanged(t *testing.T) {
+	dir, cleanup := testTempDir(t)
+	defer cleanup()
+	filename := filepath.Join(dir, "todo.txt")
+
+	// new file is only saved if it still doesn't exist
+	if err := testTasklist.LoadFromPath(testInputTasklist); err != nil {
+		t.Fatal(err)
+	}
+	fp, err := FingerprintPath(filename)
+	if err != nil {
+		t.Fatal(err)
+	} else if !fp.IsZero() {
+		t.Errorf("Expected zero Fingerprint for missing file, but got %v", fp)
+	}
+	if fp, err = testTasklist.SaveIfUnchanged(filename, fp); err != nil {
+		t.Fatal(err)
+	}
+
+	tasklist, loaded, err := LoadFromPathWithFingerprint(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.Equal(fp) {
+		t.Errorf("Expected loaded Fingerprint to equal saved Fingerprint, but got %v and %v", loaded, fp)
+	}
+	testExpected = int64(len(testTasklist.String()))
+	testGot = loaded.Size
+	if testGot != testExpected {
+		t.Errorf("Expected Fingerprint size to be %d, but got %d", testExpected, testGot)
+	}
+
+	// unchanged file is saved, touching it is no conflict
+	if err := os.Chtimes(filename, loaded.ModTime.Add(time.Hour), loaded.ModTime.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	tasklist = tasklist[:2]
+	if fp, err = tasklist.SaveIfUnchanged(filename, loaded); err != nil {
+		t.Fatal(err)
+	}
+
+	// external modification is detected
+	if err := ioutil.WriteFile(filename, []byte("External task\n"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	_, err = tasklist.SaveIfUnchanged(filename, fp)
+	if !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected ErrConflict, but got: %v", err)
+	}
+	var cerr *ConflictError
+	if !errors.As(err, &cerr) {
+		t.Fatalf("Expected *ConflictError, but got: %T", err)
+	}
+	testExpected = filename + ": " + ErrConflict.Error()
+	testGot = cerr.Error()
+	if testGot != testExpected {
+		t.Errorf("Expected error message to be [%s], but got [%s]", testExpected, testGot)
+	}
+	if !cerr.Expected.Equal(fp) || cerr.Actual.Equal(fp) {
+		t.Errorf("Expected ConflictError to hold the expected and actual Fingerprints, but got %v and %v", cerr.Expected, cerr.Actual)
+	}
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testExpected = "External task\n"
+	testGot = string(data)
+	if testGot != testExpected {
+		t.Errorf("Expected file to be kept as [%s], but got [%s]", testExpected, testGot)
+	}
+
+	// deleted file is a conflict, too
+	if err := os.Remove(filename); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = tasklist.SaveIfUnchanged(filename, fp); !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected ErrConflict, but got: %v", err)
+	}
+
+	if _, _, err := LoadFromPathWithFingerprint(filepath.Join(dir, "missing.txt")); err == nil {
+		t.Errorf("Expected LoadFromPathWithFingerprint to fail for missing file")
+	}
+	if _, err := tasklist.SaveIfUnchanged(filepath.Join(dir, "missing", "todo.txt"), Fingerprint{}); err == nil {
+		t.Errorf("Expected SaveIfUnchanged to fail for missing directory")
+	}
+}
+
+func TestParserPaths(t *testing.T) {
+	dir, cleanup := testTempDir(t)
+	defer cleanup()
+	todoPath := filepath.Join(dir, "todo.txt")
+	donePath := filepath.Join(dir, "done.txt")
+	if err := ioutil.WriteFile(todoPath, []byte("(A)  Call Mom   @Phone\nx (B) Pay rent\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	parser := NewParser(Options{PreserveFormat: true})
+
+	// the file is written with options of the parser, not the package-level variables
+	tasklist, fp, err := parser.LoadFromPathWithFingerprint(todoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tasklist[0].Todo = "Call Dad"
+	if fp, err = parser.SaveIfUnchanged(todoPath, tasklist, fp); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(todoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testExpected = "(A)  Call Dad   @Phone\nx (B) Pay rent\n"
+	testGot = string(data)
+	if testGot != testExpected {
+		t.Errorf("Expected todo file to be [%s], but got [%s]", testExpected, testGot)
+	}
+
+	if err := parser.WithLockedPath(todoPath, func(tasklist *TaskList) error {
+		(*tasklist)[0].Priority = "C"
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if tasklist, err = parser.LoadFromPath(todoPath); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := parser.Write(&buf, tasklist); err != nil {
+		t.Fatal(err)
+	}
+	testExpected = "(C)  Call Dad   @Phone\nx (B) Pay rent\n"
+	testGot = buf.String()
+	if testGot != testExpected {
+		t.Errorf("Expected TaskList to be [%s], but got [%s]", testExpected, testGot)
+	}
+
+	archiver := NewParser(Options{RemoveCompletedPriority: true})
+	if err := archiver.ArchiveToPath(todoPath, donePath, &tasklist); err != nil {
+		t.Fatal(err)
+	}
+	for filename, expected := range map[string]string{todoPath: "(C) Call Dad @Phone\n", donePath: "x Pay rent\n"} {
+		data, err = ioutil.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testExpected = expected
+		testGot = string(data)
+		if testGot != testExpected {
+			t.Errorf("Expected file to be [%s], but got [%s]", testExpected, testGot)
+		}
+	}
+
+	if err := parser.WriteToPath(filepath.Join(dir, "missing", "todo.txt"), tasklist); err == nil {
+		t.Errorf("Expected WriteToPath to fail for missing directory")
+	}
+	if _, err := parser.LoadFromPath(filepath.Join(dir, "missing.txt")); err == nil {
+		t.Errorf("Expected LoadFromPath to fail for missing file")
+	}
+}
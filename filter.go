@@ -1,17 +1,26 @@
 package todotxt
 
-import "strings"
+import (
+	"regexp"
+	"strings"
+	"time"
+)
 
 // Predicate is a function that takes a task as input and returns a bool.
 type Predicate func(Task) bool
 
 // Filter filters the current TaskList for the given predicate, and returns a new TaskList. The original TaskList is not modified.
+// Multiple predicates are combined by OR, i.e. tasks matched by any predicate are included, see FilterAll() for AND.
+// Comment and blank lines are not included in the new TaskList.
 func (tasklist TaskList) Filter(predicate Predicate, predicates ...Predicate) TaskList {
 	combined := []Predicate{predicate}
 	combined = append(combined, predicates...)
 
 	var newList TaskList
 	for _, t := range tasklist {
+		if !t.IsTask() {
+			continue
+		}
 		for _, p := range combined {
 			if p(t) {
 				newList = append(newList, t)
@@ -22,6 +31,20 @@ func (tasklist TaskList) Filter(predicate Predicate, predicates ...Predicate) Ta
 	return newList
 }
 
+// FilterAll filters the current TaskList for tasks matched by all the given predicates in a single pass, and returns a new TaskList.
+// The original TaskList is not modified. Comment and blank lines are not included in the new TaskList.
+func (tasklist TaskList) FilterAll(predicate Predicate, predicates ...Predicate) TaskList {
+	combined := All(append([]Predicate{predicate}, predicates...)...).Match
+
+	var newList TaskList
+	for _, t := range tasklist {
+		if t.IsTask() && combined(t) {
+			newList = append(newList, t)
+		}
+	}
+	return newList
+}
+
 // FilterNot returns a reversed filter for existing predicate.
 func FilterNot(predicate Predicate) Predicate {
 	return func(t Task) bool {
@@ -39,21 +62,36 @@ func FilterNotCompleted(t Task) bool {
 	return !t.Completed
 }
 
-// FilterDueToday filters tasks that are due today.
+// FilterDueToday filters tasks that are due today, according to the Clock of each task.
 func FilterDueToday(t Task) bool {
 	return t.IsDueToday()
 }
 
-// FilterOverdue filters tasks that are overdue.
+// FilterOverdue filters tasks that are overdue, according to the Clock of each task.
 func FilterOverdue(t Task) bool {
 	return t.IsOverdue()
 }
 
+// FilterActionable filters tasks that have no threshold date or the threshold date is not in the future, according to the Clock of each task.
+func FilterActionable(t Task) bool {
+	return t.IsActionable()
+}
+
 // FilterHasDueDate filters tasks that have due date.
 func FilterHasDueDate(t Task) bool {
 	return t.HasDueDate()
 }
 
+// FilterNoProject filters tasks that have no project, e.g. for an "inbox" view.
+func FilterNoProject(t Task) bool {
+	return !t.HasProjects()
+}
+
+// FilterNoContext filters tasks that have no context, e.g. for an "inbox" view.
+func FilterNoContext(t Task) bool {
+	return !t.HasContexts()
+}
+
 // FilterHasPriority filters tasks that have priority.
 func FilterHasPriority(t Task) bool {
 	return t.HasPriority()
@@ -93,3 +131,109 @@ func FilterByContext(context string) Predicate {
 		return false
 	}
 }
+
+// FilterPriorityBetween returns a filter for tasks that have a priority between the given priorities, inclusive, e.g. "A" to "C".
+// String comparison in the filters is case-insensitive.
+func FilterPriorityBetween(from, to string) Predicate {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if from > to {
+		from, to = to, from
+	}
+	return func(t Task) bool {
+		return t.HasPriority() && t.Priority >= from && t.Priority <= to
+	}
+}
+
+// FilterDueWithin returns a filter for tasks that are due today or within the next given number of days, according to the Clock of each task.
+// Overdue tasks are not included.
+func FilterDueWithin(days int) Predicate {
+	return func(t Task) bool {
+		if !t.HasDueDate() {
+			return false
+		}
+		d := daysBetween(t.now(), t.DueDate)
+		return d >= 0 && d <= days
+	}
+}
+
+// FilterDueBefore returns a filter for tasks that are due before the calendar day of the given date.
+func FilterDueBefore(date time.Time) Predicate {
+	return func(t Task) bool {
+		return t.HasDueDate() && daysBetween(date, t.DueDate) < 0
+	}
+}
+
+// FilterDueAfter returns a filter for tasks that are due after the calendar day of the given date.
+func FilterDueAfter(date time.Time) Predicate {
+	return func(t Task) bool {
+		return t.HasDueDate() && daysBetween(date, t.DueDate) > 0
+	}
+}
+
+// FilterCreatedBetween returns a filter for tasks that were created between the calendar days of the given dates, inclusive.
+// A zero time for either date leaves the range open on that side.
+func FilterCreatedBetween(from, to time.Time) Predicate {
+	return func(t Task) bool {
+		return t.HasCreatedDate() && isDateBetween(t.CreatedDate, from, to)
+	}
+}
+
+// FilterCompletedBetween returns a filter for tasks that were completed between the calendar days of the given dates, inclusive.
+// A zero time for either date leaves the range open on that side.
+func FilterCompletedBetween(from, to time.Time) Predicate {
+	return func(t Task) bool {
+		return t.Completed && t.HasCompletedDate() && isDateBetween(t.CompletedDate, from, to)
+	}
+}
+
+// isDateBetween returns true if the calendar day of date is between the calendar days of from and to, inclusive.
+// A zero time for from or to leaves the range open on that side.
+func isDateBetween(date, from, to time.Time) bool {
+	return (from.IsZero() || daysBetween(from, date) >= 0) && (to.IsZero() || daysBetween(date, to) >= 0)
+}
+
+// FilterHasTag returns a filter for tasks that have the additional tag with the given key.
+func FilterHasTag(key string) Predicate {
+	return func(t Task) bool {
+		_, found := t.AdditionalTags[key]
+		return found
+	}
+}
+
+// FilterLacksTag returns a filter for tasks that don't have the additional tag with the given key.
+func FilterLacksTag(key string) Predicate {
+	return FilterNot(FilterHasTag(key))
+}
+
+// FilterTagEquals returns a filter for tasks that have the additional tag with the given key and value.
+// String comparison in the filters is case-insensitive.
+func FilterTagEquals(key, value string) Predicate {
+	return func(t Task) bool {
+		v, found := t.AdditionalTags[key]
+		return found && strings.EqualFold(v, value)
+	}
+}
+
+// FilterTagMatches returns a filter for tasks that have the additional tag with the given key and the value matching the regular expression.
+func FilterTagMatches(key string, rx *regexp.Regexp) Predicate {
+	return func(t Task) bool {
+		v, found := t.AdditionalTags[key]
+		return found && rx.MatchString(v)
+	}
+}
+
+// FilterTodoContains returns a filter for tasks that have the given text in the todo text.
+// String comparison in the filters is case-insensitive.
+func FilterTodoContains(text string) Predicate {
+	text = strings.ToLower(text)
+	return func(t Task) bool {
+		return strings.Contains(strings.ToLower(t.Todo), text)
+	}
+}
+
+// FilterTodoMatches returns a filter for tasks that have the todo text matching the regular expression.
+func FilterTodoMatches(rx *regexp.Regexp) Predicate {
+	return func(t Task) bool {
+		return rx.MatchString(t.Todo)
+	}
+}
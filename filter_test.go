@@ -1,6 +1,9 @@
 package todotxt
 
 import (
+	"fmt"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 )
@@ -123,3 +126,105 @@ func TestTaskListFilterHelpers(t *testing.T) {
 		}
 	}
 }
+
+func TestFilterActionable(t *testing.T) {
+	testTasklist = TaskList{}
+	for _, text := range []string{
+		"Plan backyard herb garden t:2014-05-01",
+		"Pick up milk @GroceryStore",
+		"Outline chapter 5 +Novel t:2014-04-01",
+		"Call Mom t:2014-04-30",
+	} {
+		task, err := ParseTask(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testTasklist.AddTask(task)
+	}
+	testTasklist.SetClock(FixedClock(time.Date(2014, 4, 30, 9, 0, 0, 0, time.Local)))
+
+	filteredList := testTasklist.Filter(FilterActionable)
+	testExpected = 3
+	testGot = len(filteredList)
+	if testGot != testExpected {
+		t.Errorf("Expected TaskList to contain %d tasks, but got %d: [%v]", testExpected, testGot, filteredList.String())
+	}
+	testExpected = "Plan backyard herb garden t:2014-05-01"
+	testGot = testTasklist.Filter(FilterNot(FilterActionable))[0].String()
+	if testGot != testExpected {
+		t.Errorf("Expected Task to be [%s], but got [%s]", testExpected, testGot)
+	}
+}
+
+func TestTaskListFilterAll(t *testing.T) {
+	if err := testTasklist.LoadFromPath(testInputTasklist); err != nil {
+		t.Fatal(err)
+	}
+
+	testExpected = len(testTasklist.Filter(FilterCompleted).Filter(FilterHasDueDate))
+	testGot = len(testTasklist.FilterAll(FilterCompleted, FilterHasDueDate))
+	if testGot != testExpected {
+		t.Errorf("Expected TaskList to contain %d tasks, but got %d", testExpected, testGot)
+	}
+
+	testExpected = len(testTasklist.Filter(FilterCompleted))
+	testGot = len(testTasklist.FilterAll(FilterCompleted))
+	if testGot != testExpected {
+		t.Errorf("Expected TaskList to contain %d tasks, but got %d", testExpected, testGot)
+	}
+}
+
+func TestFilterBuilders(t *testing.T) {
+	tasklist, err := LoadFromReader(strings.NewReader(`(A) Call Mom @phone +Family due:2020-01-01
+(B) 2019-12-20 Write report +work due:2020-01-05 est:3
+(D) Review code @office due:2019-12-30 owner:Bob
+x 2019-12-31 2019-12-01 Buy milk
+Plan trip owner:alice
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tasklist.SetClock(FixedClock(time.Date(2020, 1, 1, 23, 0, 0, 0, time.Local)))
+	date := func(s string) time.Time {
+		d, err := parseTime(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	for i, tc := range []struct {
+		predicate Predicate
+		expected  []int
+	}{
+		{FilterPriorityBetween("a", "C"), []int{1, 2}},
+		{FilterPriorityBetween("D", "B"), []int{2, 3}},
+		{FilterDueWithin(0), []int{1}},
+		{FilterDueWithin(4), []int{1, 2}},
+		{FilterDueBefore(date("2020-01-01")), []int{3}},
+		{FilterDueAfter(date("2020-01-01")), []int{2}},
+		{FilterCreatedBetween(date("2019-12-01"), date("2019-12-19")), []int{4}},
+		{FilterCreatedBetween(time.Time{}, date("2019-12-20")), []int{2, 4}},
+		{FilterCompletedBetween(date("2019-12-31"), time.Time{}), []int{4}},
+		{FilterCompletedBetween(date("2020-01-01"), time.Time{}), nil},
+		{FilterHasTag("owner"), []int{3, 5}},
+		{FilterLacksTag("owner"), []int{1, 2, 4}},
+		{FilterTagEquals("owner", "bob"), []int{3}},
+		{FilterTagMatches("owner", regexp.MustCompile(`^a`)), []int{5}},
+		{FilterTodoContains("MOM"), []int{1}},
+		{FilterTodoMatches(regexp.MustCompile(`^(Buy|Plan) `)), []int{4, 5}},
+		{FilterNoProject, []int{3, 4, 5}},
+		{FilterNoContext, []int{2, 4, 5}},
+		{All(FilterNoProject, FilterNoContext).Match, []int{4, 5}},
+	} {
+		var ids []int
+		for _, task := range tasklist.Filter(tc.predicate) {
+			ids = append(ids, task.ID)
+		}
+		testExpected = fmt.Sprint(tc.expected)
+		testGot = fmt.Sprint(ids)
+		if testGot != testExpected {
+			t.Errorf("(%d) Expected filtered tasks to be %s, but got %s", i, testExpected, testGot)
+		}
+	}
+}
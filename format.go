@@ -0,0 +1,325 @@
+package todotxt
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	dateTokenRx     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)                  // Match a single date token: '2012-12-12'
+	dateTimeTokenRx = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}(?:T\d{2}:\d{2})?$`) // Match a single date token with optional time of day: '2012-12-12T17:00'
+	priorityTokenRx = regexp.MustCompile(`^\([A-Z]\)$`)                          // Match a single priority token: '(A)'
+	addonTagTokenRx = regexp.MustCompile(`^([^:\s]+):([^:\s]+(?::\d{2})*)$`)     // Match a single additional tag token: 'due:2012-12-12' or 'time:17:00'
+)
+
+// textToken represents a whitespace-separated word of a task string, together with the whitespaces before it.
+type textToken struct {
+	space string
+	word  string
+}
+
+// splitTokens splits the text into tokens, keeping the whitespaces before each word.
+func splitTokens(s string) []textToken {
+	var (
+		tokens []textToken
+		start  int
+	)
+	for start < len(s) {
+		wordStart := start + len(s[start:]) - len(strings.TrimLeft(s[start:], whitespaces))
+		if wordStart >= len(s) {
+			break
+		}
+		wordEnd := strings.IndexAny(s[wordStart:], whitespaces)
+		if wordEnd < 0 {
+			wordEnd = len(s)
+		} else {
+			wordEnd += wordStart
+		}
+		tokens = append(tokens, textToken{space: s[start:wordStart], word: s[wordStart:wordEnd]})
+		start = wordEnd
+	}
+	return tokens
+}
+
+// joinTokens joins the tokens back into a string.
+func joinTokens(tokens []textToken) string {
+	var sb strings.Builder
+	for _, t := range tokens {
+		sb.WriteString(t.space)
+		sb.WriteString(t.word)
+	}
+	return sb.String()
+}
+
+// prefixTokenCount returns the number of leading tokens holding the completion mark, completed date, priority and created date.
+func (p *Parser) prefixTokenCount(tokens []textToken) int {
+	i := 0
+	if len(tokens) > 1 && tokens[0].word == "x" {
+		i++
+		if len(tokens) > i+1 && dateTimeTokenRx.MatchString(tokens[i].word) {
+			i++
+		}
+	}
+	if len(tokens) > i+1 && priorityTokenRx.MatchString(tokens[i].word) {
+		i++
+	}
+	if len(tokens) > i+1 && (dateTokenRx.MatchString(tokens[i].word) || p.isCreatedRelativeDate(tokens[i].word)) {
+		i++
+	}
+	return i
+}
+
+// preservedString returns the task string in todo.txt format, keeping the token order and spacing of Task.Original.
+//
+// Unchanged tasks are returned as Task.Original. For modified tasks, only the changed tokens are rewritten,
+// removed tokens are dropped, and new contexts, projects, tags, threshold and due date are appended at the end.
+// Changed todo text is rewritten word by word, so contexts and projects between words keep their positions.
+// Relative dates are rewritten as absolute dates if RewriteRelativeDates is enabled.
+// If the Original text can't be reused consistently, the canonical format is returned.
+func (p *Parser) preservedString(task *Task) string {
+	canonical := p.canonicalString(task)
+	orig, err := p.parseOriginal(task)
+	if err != nil {
+		return canonical
+	}
+	rewriteDates := p.opts.RewriteRelativeDates && p.hasRelativeDates(task.Original)
+	if p.canonicalString(orig) == canonical && !rewriteDates {
+		return task.Original
+	}
+
+	// rewrite changed todo text word by word first, and as a whole if the spacing of words doesn't match
+	for _, byWord := range []bool{true, false} {
+		text := p.rewriteOriginal(task, orig, byWord)
+		if parsed, err := p.Parse(text); err == nil && p.canonicalString(parsed) == canonical {
+			return text
+		}
+		if orig.Todo == task.Todo {
+			break
+		}
+	}
+	return canonical
+}
+
+// parsedOriginal is the task parsed from Task.Original, cached by Parser.Parse() for formatting with PreserveFormat.
+type parsedOriginal struct {
+	text   string // Task.Original the task was parsed from.
+	layout string // Date layout the task was parsed with.
+	task   Task   // Parsed task.
+}
+
+// parseOriginal returns the task parsed from Task.Original, from the cache if Task.Original and the date layout are unchanged.
+func (p *Parser) parseOriginal(task *Task) (*Task, error) {
+	if c := task.parsed; c != nil && c.text == task.Original && c.layout == p.opts.DateLayout {
+		return &c.task, nil
+	}
+	return p.Parse(task.Original)
+}
+
+// isTodoWord returns true if the token is a word of the todo text, rather than a context, project or additional tag.
+func isTodoWord(word string) bool {
+	return !(len(word) > 1 && (word[0] == '@' || word[0] == '+')) && !addonTagTokenRx.MatchString(word)
+}
+
+// diffWords returns whether each word of a is kept in b by their longest common subsequence,
+// and the words of b inserted before each word of a, with the words inserted at the end as the last element.
+func diffWords(a, b []string) (keep []bool, inserts [][]string) {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	keep = make([]bool, len(a))
+	inserts = make([][]string, len(a)+1)
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			keep[i] = true
+			i++
+			j++
+		case lcs[i+1][j] > lcs[i][j+1]:
+			i++
+		default:
+			inserts[i] = append(inserts[i], b[j])
+			j++
+		}
+	}
+	inserts[len(a)] = append(inserts[len(a)], b[j:]...)
+	return keep, inserts
+}
+
+// rewriteOriginal rewrites the tokens of Task.Original which differ from the parsed original task.
+// If the todo text is changed, it's rewritten word by word if byWord is 'true', or as a whole at the position of its first word otherwise.
+func (p *Parser) rewriteOriginal(task, orig *Task, byWord bool) string {
+	tokens := splitTokens(task.Original)
+	n := p.prefixTokenCount(tokens)
+
+	prefix := joinTokens(tokens[:n])
+	if p.prefixString(orig) != p.prefixString(task) || (p.opts.RewriteRelativeDates && n > 0 && p.isCreatedRelativeDate(tokens[n-1].word)) {
+		prefix = strings.TrimRight(p.prefixString(task), whitespaces)
+	}
+
+	contexts := stringSet(task.Contexts)
+	projects := stringSet(task.Projects)
+	seenContexts := make(map[string]bool)
+	seenProjects := make(map[string]bool)
+	seenTags := make(map[string]bool)
+	todoChanged := orig.Todo != task.Todo
+	todoWritten := false
+
+	// word by word changes of the todo text
+	var (
+		todoWords []string
+		keep      []bool
+		inserts   [][]string
+	)
+	if todoChanged && byWord {
+		for _, t := range tokens[n:] {
+			if isTodoWord(t.word) {
+				todoWords = append(todoWords, t.word)
+			}
+		}
+		keep, inserts = diffWords(todoWords, strings.Fields(task.Todo))
+	}
+	k := 0 // Index of the current word in todoWords
+
+	var body []textToken
+	appendWords := func(words []string) {
+		for _, word := range words {
+			body = append(body, textToken{space: " ", word: word})
+		}
+	}
+	for _, t := range tokens[n:] {
+		switch {
+		case len(t.word) > 1 && t.word[0] == '@':
+			if name := t.word[1:]; contexts[name] {
+				seenContexts[name] = true
+				body = append(body, t)
+			}
+		case len(t.word) > 1 && t.word[0] == '+':
+			if name := t.word[1:]; projects[name] {
+				seenProjects[name] = true
+				body = append(body, t)
+			}
+		case addonTagTokenRx.MatchString(t.word):
+			match := addonTagTokenRx.FindStringSubmatch(t.word)
+			key := match[1]
+			if seenTags[key] {
+				continue
+			}
+			if key == "due" || key == "t" {
+				if value := p.dateTagValue(task, key); isNotEmpty(value) {
+					seenTags[key] = true
+					if p.dateTagValue(orig, key) != value || (p.opts.RewriteRelativeDates && p.isRelativeDate(match[2])) {
+						t.word = key + ":" + value
+					}
+					body = append(body, t)
+				}
+			} else if value, found := task.AdditionalTags[key]; found {
+				seenTags[key] = true
+				t.word = key + ":" + value
+				body = append(body, t)
+			}
+		default:
+			if !todoChanged {
+				body = append(body, t)
+			} else if byWord {
+				appendWords(inserts[k])
+				if keep[k] {
+					body = append(body, t)
+				}
+				if k++; k == len(todoWords) {
+					appendWords(inserts[k])
+					todoWritten = true
+				}
+			} else if !todoWritten {
+				todoWritten = true
+				if isNotEmpty(task.Todo) {
+					t.word = task.Todo
+					body = append(body, t)
+				}
+			}
+		}
+	}
+	if todoChanged && !todoWritten && isNotEmpty(task.Todo) {
+		body = append([]textToken{{space: " ", word: task.Todo}}, body...)
+	}
+
+	// Append new tokens in the same order as String()
+	appendNew := func(word string) {
+		body = append(body, textToken{space: " ", word: word})
+	}
+	for _, context := range sortedStrings(task.Contexts) {
+		if !seenContexts[context] {
+			seenContexts[context] = true
+			appendNew("@" + context)
+		}
+	}
+	for _, project := range sortedStrings(task.Projects) {
+		if !seenProjects[project] {
+			seenProjects[project] = true
+			appendNew("+" + project)
+		}
+	}
+	for _, key := range task.sortedTagKeys() {
+		if !seenTags[key] {
+			appendNew(key + ":" + task.AdditionalTags[key])
+		}
+	}
+	if task.HasThresholdDate() && !seenTags["t"] {
+		appendNew("t:" + p.formatTime(task.ThresholdDate))
+	}
+	if task.HasDueDate() && !seenTags["due"] {
+		appendNew("due:" + p.formatDueDate(task))
+	}
+
+	if len(body) > 0 {
+		if isEmpty(prefix) {
+			body[0].space = emptyStr
+		} else if isEmpty(body[0].space) {
+			body[0].space = " "
+		}
+	}
+	return prefix + joinTokens(body)
+}
+
+// dateTagValue returns the formatted date field of the task for known date tags: "due" and "t", or empty string if it's not set.
+func (p *Parser) dateTagValue(task *Task, key string) string {
+	switch {
+	case key == "t" && task.HasThresholdDate():
+		return p.formatTime(task.ThresholdDate)
+	case key == "due" && task.HasDueDate():
+		return p.formatDueDate(task)
+	}
+	return emptyStr
+}
+
+// stringSet returns a set of the given strings.
+func stringSet(list []string) map[string]bool {
+	set := make(map[string]bool, len(list))
+	for _, s := range list {
+		set[s] = true
+	}
+	return set
+}
+
+// sortedStrings returns a sorted copy of the given strings.
+func sortedStrings(list []string) []string {
+	sorted := make([]string, len(list))
+	copy(sorted, list)
+	sort.Strings(sorted)
+	return sorted
+}
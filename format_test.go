@@ -0,0 +1,146 @@
+package todotxt
+
+import (
+	"testing"
+	"time"
+)
+
+func BenchmarkTask_PreservedString(b *testing.B) {
+	PreserveFormat = true
+	defer func() { PreserveFormat = false }()
+
+	task, _ := ParseTask("(C) 2014-01-01 @Go Create golang due:2014-01-12 library +go-todotxt  documentation")
+	task.Priority = "A"
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = task.String()
+	}
+}
+
+func TestSplitTokens(t *testing.T) {
+	cases := []struct {
+		text   string
+		tokens []textToken
+	}{
+		{"", nil},
+		{"   ", nil},
+		{"a", []textToken{{"", "a"}}},
+		{"a  b\tc ", []textToken{{"", "a"}, {"  ", "b"}, {"\t", "c"}}},
+		{" x (A) +p", []textToken{{" ", "x"}, {" ", "(A)"}, {" ", "+p"}}},
+	}
+	for _, c := range cases {
+		got := splitTokens(c.text)
+		if len(got) != len(c.tokens) {
+			t.Errorf("Expected %d tokens for [%s], but got %d: %v", len(c.tokens), c.text, len(got), got)
+			continue
+		}
+		for i := range got {
+			if got[i] != c.tokens[i] {
+				t.Errorf("Expected token[%d] of [%s] to be %v, but got %v", i, c.text, c.tokens[i], got[i])
+			}
+		}
+	}
+}
+
+func TestTaskPreserveFormat(t *testing.T) {
+	PreserveFormat = true
+	defer func() { PreserveFormat = false }()
+
+	due, _ := parseTime("2020-02-02")
+	cases := []struct {
+		text     string
+		modify   func(task *Task)
+		expected string
+	}{
+		{"(B) @Go Create  golang +go-todotxt library\tdocumentation due:2014-01-12 hello:world",
+			func(task *Task) {},
+			"(B) @Go Create  golang +go-todotxt library\tdocumentation due:2014-01-12 hello:world"},
+		{"(B) @Go Create  golang +go-todotxt library due:2014-01-12",
+			func(task *Task) { task.Priority = "A" },
+			"(A) @Go Create  golang +go-todotxt library due:2014-01-12"},
+		{"(B) 2014-01-01 @Go Create  golang +go-todotxt library due:2014-01-12",
+			func(task *Task) { task.DueDate = due },
+			"(B) 2014-01-01 @Go Create  golang +go-todotxt library due:2020-02-02"},
+		{"Create  golang +go-todotxt library",
+			func(task *Task) { task.DueDate = due },
+			"Create  golang +go-todotxt library due:2020-02-02"},
+		{"Create golang due:2014-01-12 +go-todotxt library",
+			func(task *Task) { task.DueDate = time.Time{} },
+			"Create golang +go-todotxt library"},
+		{"Create @Go golang +go-todotxt library",
+			func(task *Task) {
+				task.Contexts = []string{"Home", "Go"}
+				task.Projects = nil
+			},
+			"Create @Go golang library @Home"},
+		{"Create golang level:1 library more:2",
+			func(task *Task) {
+				task.AdditionalTags["level"] = "5"
+				delete(task.AdditionalTags, "more")
+				task.AdditionalTags["new"] = "tag"
+			},
+			"Create golang level:5 library new:tag"},
+		{"(A) Create golang +go-todotxt library",
+			func(task *Task) { task.Complete() },
+			"x " + time.Now().Format(DateLayout) + " (A) Create golang +go-todotxt library"},
+		{"x 2014-01-02 (A) Create golang +go-todotxt library",
+			func(task *Task) { task.Reopen() },
+			"(A) Create golang +go-todotxt library"},
+		{"Create @Go golang +go-todotxt library",
+			func(task *Task) { task.Todo = "Write docs" },
+			"Write docs @Go +go-todotxt"},
+		{"@Go +go-todotxt",
+			func(task *Task) { task.Todo = "Write docs" },
+			"Write docs @Go +go-todotxt"},
+		{"(A) Create golang",
+			func(task *Task) { task.CreatedDate = due },
+			"(A) 2020-02-02 Create golang"},
+		{"Create t:2014-01-12 golang due:2014-01-12",
+			func(task *Task) { task.ThresholdDate = due },
+			"Create t:2020-02-02 golang due:2014-01-12"},
+		{"Create golang due:2014-01-12",
+			func(task *Task) { task.ThresholdDate = due },
+			"Create golang due:2014-01-12 t:2020-02-02"},
+		{"Call @home Mom +family tomorrow",
+			func(task *Task) { task.Todo = "Call Dad tomorrow" },
+			"Call @home Dad +family tomorrow"},
+		{"Call @home Mom +family tomorrow",
+			func(task *Task) { task.Todo = "Please call Mom tomorrow evening" },
+			"Please call @home Mom +family tomorrow evening"},
+		{"Call @home Mom +family tomorrow",
+			func(task *Task) { task.Todo = "Call  Mom" },
+			"Call  Mom @home +family"},
+	}
+	for _, c := range cases {
+		task, err := ParseTask(c.text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.modify(task)
+		if got := task.String(); got != c.expected {
+			t.Errorf("Expected Task [%s] to be [%s], but got [%s]", c.text, c.expected, got)
+		}
+	}
+
+	// tasks without original text are formatted as usual
+	task := Task{Todo: "Go shopping..", Contexts: []string{"GroceryStore"}}
+	testExpected = "Go shopping.. @GroceryStore"
+	testGot = task.String()
+	if testGot != testExpected {
+		t.Errorf("Expected Task to be [%s], but got [%s]", testExpected, testGot)
+	}
+}
+
+func TestTaskListPreserveFormat(t *testing.T) {
+	PreserveFormat = true
+	defer func() { PreserveFormat = false }()
+
+	if err := testTasklist.LoadFromPath(testInputTasklist); err != nil {
+		t.Fatal(err)
+	}
+	for _, task := range testTasklist {
+		if got := task.String(); got != task.Original {
+			t.Errorf("Expected unchanged Task to be [%s], but got [%s]", task.Original, got)
+		}
+	}
+}
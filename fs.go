@@ -0,0 +1,28 @@
+//go:build go1.16
+// +build go1.16
+
+package todotxt
+
+import "io/fs"
+
+// LoadFromFS loads a TaskList from the named file in fs.FS, e.g. an embed.FS or os.DirFS. It requires Go 1.16 or later.
+//
+// Note: This will clear the current TaskList and overwrite it's contents with whatever is in the file.
+func (tasklist *TaskList) LoadFromFS(fsys fs.FS, name string) error {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return tasklist.LoadFromReader(file)
+}
+
+// LoadFromFS loads and returns a TaskList from the named file in fs.FS, e.g. an embed.FS or os.DirFS.
+func LoadFromFS(fsys fs.FS, name string) (TaskList, error) {
+	tasklist := TaskList{}
+	if err := tasklist.LoadFromFS(fsys, name); err != nil {
+		return failedTaskList(tasklist, err)
+	}
+	return tasklist, nil
+}
@@ -0,0 +1,43 @@
+//go:build go1.16
+// +build go1.16
+
+package todotxt
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadFromFS(t *testing.T) {
+	if testTasklist, err := LoadFromFS(os.DirFS("testdata"), "tasklist_todo.txt"); err != nil {
+		t.Fatal(err)
+	} else {
+		data, err := ioutil.ReadFile(testExpectedOutput)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testExpected = string(data)
+		testGot = testTasklist.String()
+		if testGot != testExpected {
+			t.Errorf("Expected TaskList to be [%s], but got [%s]", testExpected, testGot)
+		}
+	}
+
+	fsys := fstest.MapFS{
+		"todo.txt": &fstest.MapFile{Data: []byte("(A) Call Mom @Phone +Family\nx Download Todo.txt mobile app @Phone\n")},
+	}
+	if err := testTasklist.LoadFromFS(fsys, "todo.txt"); err != nil {
+		t.Fatal(err)
+	}
+	testExpected = 2
+	testGot = len(testTasklist)
+	if testGot != testExpected {
+		t.Errorf("Expected TaskList to contain %d tasks, but got %d", testExpected, testGot)
+	}
+
+	if testTasklist, err := LoadFromFS(fsys, "some_file_that_does_not_exists.txt"); testTasklist != nil || err == nil {
+		t.Errorf("Expected LoadFromFS to fail, but got TaskList back: [%s]", testTasklist)
+	}
+}
@@ -0,0 +1,150 @@
+package todotxt
+
+import "time"
+
+// timeLayout is used for formatting the time of day of due dates, e.g. "due:2020-01-01T17:00" or "time:17:00".
+const timeLayout = "15:04"
+
+// locationOrDefault returns the given location, or Location if it's nil, or time.Local if both are nil.
+func locationOrDefault(loc *time.Location) *time.Location {
+	if loc != nil {
+		return loc
+	}
+	if Location != nil {
+		return Location
+	}
+	return time.Local
+}
+
+// location returns the location of the parser for parsing dates.
+func (p *Parser) location() *time.Location {
+	return locationOrDefault(p.opts.Location)
+}
+
+// Location returns the location for calendar days of the task, see SetLocation().
+func (task *Task) Location() *time.Location {
+	return locationOrDefault(task.loc)
+}
+
+// SetLocation sets the location for calendar days of the task, e.g. in IsOverdue() and IsDueToday().
+// If loc is nil, the package-level Location is used. Parsed tasks have the location of the parser.
+func (task *Task) SetLocation(loc *time.Location) {
+	task.loc = loc
+}
+
+// SetLocation sets the location for calendar days of all tasks in the TaskList. If loc is nil, the package-level Location is used.
+// Tasks added later by AddTask() get the location too, unless the TaskList is empty.
+func (tasklist *TaskList) SetLocation(loc *time.Location) {
+	for i := range *tasklist {
+		(*tasklist)[i].loc = loc
+	}
+}
+
+// HasDueTime returns true if the task has a due date with time of day,
+// given by "due:2020-01-01T17:00" or "time:17:00" tag, or set by SetDueTime().
+func (task *Task) HasDueTime() bool {
+	return task.HasDueDate() && task.dueTime
+}
+
+// SetDueTime sets the due date of the task with time of day. If due is zero time, the due date is removed.
+// Setting the DueDate field directly keeps whether the due date has time of day.
+func (task *Task) SetDueTime(due time.Time) {
+	task.DueDate = due
+	task.dueTime = !due.IsZero()
+}
+
+// ClearDueTime removes the time of day of the due date, so the task is due at the end of the due date.
+func (task *Task) ClearDueTime() {
+	if task.dueTime {
+		year, month, day := task.DueDate.Date()
+		task.DueDate = time.Date(year, month, day, 0, 0, 0, 0, task.DueDate.Location())
+		task.dueTime = false
+	}
+}
+
+// withTimeOfDay returns the date with the hour and minute of the given time.
+func withTimeOfDay(date, clock time.Time) time.Time {
+	year, month, day := date.Date()
+	return time.Date(year, month, day, clock.Hour(), clock.Minute(), 0, 0, date.Location())
+}
+
+// parseDueDate parses the due date string with the date layout of the parser, optionally followed by the time of day, e.g. "2020-01-01T17:00".
+// It returns whether the due date has time of day.
+func (p *Parser) parseDueDate(s string) (time.Time, bool, error) {
+	if date, err := time.ParseInLocation(p.opts.DateLayout+"T"+timeLayout, s, p.location()); err == nil {
+		return date, true, nil
+	}
+	date, err := p.parseDate(s)
+	return date, false, err
+}
+
+// applyTimeTag sets the time of day of the due date given by "time:" tag, unless the due date has time of day already.
+// Invalid values of the tag are ignored.
+func applyTimeTag(task *Task) {
+	if !task.HasDueDate() || task.dueTime {
+		return
+	}
+	if clock, err := time.Parse(timeLayout, task.AdditionalTags[TimeTag]); err == nil {
+		task.SetDueTime(withTimeOfDay(task.DueDate, clock))
+	}
+}
+
+// formatDueDate formats the due date of the task with the date layout of the parser,
+// followed by the time of day unless it's given by "time:" tag, e.g. "2020-01-01T17:00".
+func (p *Parser) formatDueDate(task *Task) string {
+	date := p.formatTime(task.DueDate)
+	if clock := task.DueDate.Format(timeLayout); task.HasDueTime() && task.AdditionalTags[TimeTag] != clock {
+		date += "T" + clock
+	}
+	return date
+}
+
+// dueDateValue returns the due date of the task as string with time of day if it has, or empty string without due date.
+func dueDateValue(task *Task) string {
+	date := dateValue(task.DueDate)
+	if task.HasDueTime() {
+		date += "T" + task.DueDate.Format(timeLayout)
+	}
+	return date
+}
+
+// HasCompletedTime returns true if the task has a completed date with time of day, given by "x 2020-01-01T17:00", or set by SetCompletedTime().
+func (task *Task) HasCompletedTime() bool {
+	return task.HasCompletedDate() && task.completedTime
+}
+
+// SetCompletedTime completes the task with the completed date with time of day. If completed is zero time, the task is reopened.
+// Setting the CompletedDate field directly keeps whether the completed date has time of day.
+func (task *Task) SetCompletedTime(completed time.Time) {
+	task.Completed = !completed.IsZero()
+	task.CompletedDate = completed
+	task.completedTime = task.Completed
+}
+
+// parseCompletedDate parses the completed date string with the date layout of the parser, optionally followed by the time of day.
+// It returns whether the completed date has time of day. Completed dates are never relative dates.
+func (p *Parser) parseCompletedDate(s string) (time.Time, bool, error) {
+	if date, err := time.ParseInLocation(p.opts.DateLayout+"T"+timeLayout, s, p.location()); err == nil {
+		return date, true, nil
+	}
+	date, err := p.parseTime(s)
+	return date, false, err
+}
+
+// formatCompletedDate formats the completed date of the task with the date layout of the parser, followed by the time of day if it has.
+func (p *Parser) formatCompletedDate(task *Task) string {
+	date := p.formatTime(task.CompletedDate)
+	if task.HasCompletedTime() {
+		date += "T" + task.CompletedDate.Format(timeLayout)
+	}
+	return date
+}
+
+// completedDateValue returns the completed date of the task as string with time of day if it has, or empty string without completed date.
+func completedDateValue(task *Task) string {
+	date := dateValue(task.CompletedDate)
+	if task.HasCompletedTime() {
+		date += "T" + task.CompletedDate.Format(timeLayout)
+	}
+	return date
+}
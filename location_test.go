@@ -0,0 +1,183 @@
+package todotxt
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTaskDueTime(t *testing.T) {
+	parser := NewParser(Options{Location: time.UTC})
+
+	tests := []struct {
+		text    string
+		due     string
+		hasTime bool
+	}{
+		{"Call Mom due:2024-05-01T17:00", "2024-05-01T17:00:00Z", true},
+		{"Call Mom time:17:00 due:2024-05-01", "2024-05-01T17:00:00Z", true},
+		{"Call Mom time:17:00 due:2024-05-01T09:30", "2024-05-01T09:30:00Z", true},
+		{"Call Mom time:late due:2024-05-01", "2024-05-01T00:00:00Z", false},
+		{"Call Mom due:2024-05-01", "2024-05-01T00:00:00Z", false},
+	}
+	for _, tt := range tests {
+		task, err := parser.Parse(tt.text)
+		if err != nil {
+			t.Errorf("Expected [%s] to be parsed, but got error: %v", tt.text, err)
+			continue
+		}
+		testExpected = tt.due
+		testGot = task.DueDate.Format(time.RFC3339)
+		if testGot != testExpected {
+			t.Errorf("Expected [%s] to be due at [%s], but got [%s]", tt.text, testExpected, testGot)
+		}
+		if task.HasDueTime() != tt.hasTime {
+			t.Errorf("Expected [%s] to have due time %v, but got %v", tt.text, tt.hasTime, task.HasDueTime())
+		}
+		testExpected = tt.text
+		testGot = parser.Format(task)
+		if testGot != testExpected {
+			t.Errorf("Expected Task to be [%s], but got [%s]", testExpected, testGot)
+		}
+	}
+
+	if _, err := parser.Parse("Call Mom due:2024-05-01T25:00"); err == nil {
+		t.Errorf("Expected Parse to fail for invalid due time, but it didn't")
+	}
+
+	task, _ := parser.Parse("Call Mom")
+	task.SetDueTime(time.Date(2024, 5, 1, 8, 15, 0, 0, time.UTC))
+	testExpected = "Call Mom due:2024-05-01T08:15"
+	testGot = parser.Format(task)
+	if testGot != testExpected {
+		t.Errorf("Expected Task to be [%s], but got [%s]", testExpected, testGot)
+	}
+	task.ClearDueTime()
+	testExpected = "Call Mom due:2024-05-01"
+	testGot = parser.Format(task)
+	if testGot != testExpected || task.HasDueTime() {
+		t.Errorf("Expected Task to be [%s] without due time, but got [%s]", testExpected, testGot)
+	}
+}
+
+func TestTaskCompletedTime(t *testing.T) {
+	parser := NewParser(Options{Location: time.UTC, PreserveFormat: true})
+
+	text := "x 2024-05-01T17:00 (A) 2024-04-30 Call Mom"
+	task, err := parser.Parse(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testExpected = "2024-05-01T17:00:00Z (A) 2024-04-30 Call Mom"
+	testGot = task.CompletedDate.Format(time.RFC3339) + " (" + task.Priority + ") " + dateValue(task.CreatedDate) + " " + task.Todo
+	if testGot != testExpected || !task.HasCompletedTime() {
+		t.Errorf("Expected Task to be [%s] with completed time, but got [%s]", testExpected, testGot)
+	}
+	testExpected = text
+	testGot = parser.Format(task)
+	if testGot != testExpected {
+		t.Errorf("Expected Task to be [%s], but got [%s]", testExpected, testGot)
+	}
+	task.Todo = "Call Dad"
+	testExpected = "x 2024-05-01T17:00 (A) 2024-04-30 Call Dad"
+	testGot = parser.Format(task)
+	if testGot != testExpected {
+		t.Errorf("Expected Task to be [%s], but got [%s]", testExpected, testGot)
+	}
+
+	if _, err := parser.Parse("x 2024-05-01T25:00 Call Mom"); err == nil {
+		t.Errorf("Expected Parse to fail for invalid completed time, but it didn't")
+	}
+
+	task.Reopen()
+	task.Complete()
+	if task.HasCompletedTime() {
+		t.Errorf("Expected completed Task to have no completed time")
+	}
+	task.SetCompletedTime(time.Date(2024, 5, 2, 8, 15, 0, 0, time.UTC))
+	testExpected = "x 2024-05-02T08:15 (A) 2024-04-30 Call Dad"
+	testGot = parser.Format(task)
+	if testGot != testExpected {
+		t.Errorf("Expected Task to be [%s], but got [%s]", testExpected, testGot)
+	}
+}
+
+func TestTaskDueTimeStatus(t *testing.T) {
+	parser := NewParser(Options{Location: time.UTC})
+	task, err := parser.Parse("Call Mom due:2024-05-01T17:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	task.SetClock(FixedClock(time.Date(2024, 5, 1, 16, 0, 0, 0, time.UTC)))
+	if task.IsOverdue() || !task.IsDueToday() || task.Due() != time.Hour {
+		t.Errorf("Expected Task to be due today in 1 hour, but got overdue %v, due today %v, due in %v", task.IsOverdue(), task.IsDueToday(), task.Due())
+	}
+	task.SetClock(FixedClock(time.Date(2024, 5, 1, 18, 0, 0, 0, time.UTC)))
+	if !task.IsOverdue() || !task.IsDueToday() || task.Due() != -time.Hour {
+		t.Errorf("Expected Task to be overdue since 1 hour, but got overdue %v, due today %v, due in %v", task.IsOverdue(), task.IsDueToday(), task.Due())
+	}
+
+	task, err = parser.Parse("Pay rent due:2024-05-01T17:00 rec:1w")
+	if err != nil {
+		t.Fatal(err)
+	}
+	task.SetClock(FixedClock(time.Date(2024, 5, 2, 9, 0, 0, 0, time.UTC)))
+	next, err := task.NextRecurrence()
+	if err != nil {
+		t.Fatal(err)
+	}
+	testExpected = "Pay rent rec:1w due:2024-05-09T17:00"
+	testGot = parser.Format(next)
+	if testGot != testExpected {
+		t.Errorf("Expected next Task to be [%s], but got [%s]", testExpected, testGot)
+	}
+}
+
+func TestTaskListLocation(t *testing.T) {
+	tokyo := time.FixedZone("UTC+9", 9*60*60)
+	clock := FixedClock(time.Date(2024, 5, 1, 20, 0, 0, 0, time.UTC)) // 2024-05-02 05:00 in UTC+9
+
+	parser := NewParser(Options{Location: time.UTC, Clock: clock})
+	tasklist, err := parser.Load(strings.NewReader("Call Mom due:2024-05-01\nPay rent due:2024-05-02\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testExpected = "[false false] [true false]"
+	testGot = dueStatus(tasklist)
+	if testGot != testExpected {
+		t.Errorf("Expected overdue and due today in UTC to be %s, but got %s", testExpected, testGot)
+	}
+
+	tasklist.SetLocation(tokyo)
+	testExpected = "[true false] [false true]"
+	testGot = dueStatus(tasklist)
+	if testGot != testExpected {
+		t.Errorf("Expected overdue and due today in UTC+9 to be %s, but got %s", testExpected, testGot)
+	}
+	if tasklist[0].Location() != tokyo {
+		t.Errorf("Expected Task to have location [%v], but got [%v]", tokyo, tasklist[0].Location())
+	}
+
+	task, err := NewParser(Options{Location: tokyo, Clock: clock}).Parse("x 2024-05-02 2024-05-01 Call Mom due:2024-05-02")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testExpected = "2024-05-02T00:00:00+09:00 2024-05-01T00:00:00+09:00 true"
+	testGot = task.CompletedDate.Format(time.RFC3339) + " " + task.CreatedDate.Format(time.RFC3339) + " " + fmt.Sprint(task.IsDueToday())
+	if testGot != testExpected {
+		t.Errorf("Expected Task dates to be [%s], but got [%s]", testExpected, testGot)
+	}
+}
+
+// dueStatus returns whether the tasks are overdue and due today.
+func dueStatus(tasklist TaskList) string {
+	var overdue, today []bool
+	for i := range tasklist {
+		overdue = append(overdue, tasklist[i].IsOverdue())
+		today = append(today, tasklist[i].IsDueToday())
+	}
+	return fmt.Sprint(overdue, " ", today)
+}
@@ -0,0 +1,30 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package todotxt
+
+import (
+	"os"
+	"time"
+)
+
+// lockPath acquires an exclusive lock by creating the lock file of filename exclusively, and blocks until the lock is acquired.
+// It returns a function to release the lock by removing the lock file.
+//
+// If a process crashes while holding the lock, the lock file has to be removed manually.
+func lockPath(filename string) (func() error, error) {
+	lockname := filename + ".lock"
+	for {
+		file, err := os.OpenFile(lockname, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0640)
+		if err == nil {
+			_ = file.Close()
+			return func() error {
+				return os.Remove(lockname)
+			}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,26 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package todotxt
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockPath acquires an exclusive advisory lock on the lock file of filename with flock(2), and blocks until the lock is acquired.
+// It returns a function to release the lock.
+func lockPath(filename string) (func() error, error) {
+	file, err := os.OpenFile(filename+".lock", os.O_RDWR|os.O_CREATE, 0640)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return func() error {
+		defer file.Close()
+		return syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+	}, nil
+}
@@ -0,0 +1,338 @@
+package todotxt
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// minTaskSimilarity is the minimum similarity of todo texts for matching tasks without an identical todo text.
+const minTaskSimilarity = 0.5
+
+// MergeConflict represents a field of a task which was changed differently in both TaskLists by Merge().
+type MergeConflict struct {
+	ID     int             // ID of the task in the merged TaskList.
+	Field  TaskSegmentType // Conflicting field, or zero if the task was removed in one TaskList and modified in the other one.
+	Tag    string          // Key of the conflicting additional tag, if Field is SegmentTag.
+	Base   string          // Value of the field in base TaskList, empty if not set.
+	Ours   string          // Value of the field in our TaskList, empty if not set.
+	Theirs string          // Value of the field in their TaskList, empty if not set.
+}
+
+// Merge merges the changes of two TaskLists, which were both modified from the same base TaskList, e.g. conflicting copies of a synced todo.txt file.
+//
+// Tasks are matched by identity rather than by position: tasks with the same "id:" tag, tasks with the same todo text,
+// or tasks with the most similar todo text. Field-level changes of matched tasks in either TaskList are merged,
+// contexts and projects are merged as sets, and additional tags are merged by key.
+// Tasks added in either TaskList are kept, tasks added in both TaskLists with the same "id:" tag or todo text are merged as one task, and tasks removed in either TaskList are removed if they were not modified in the other one.
+//
+// The merged TaskList keeps the order, IDs and comment lines of ours, with tasks added only in theirs appended at the end.
+// For each real conflict, i.e. a field changed to different values in both TaskLists, our value is kept and a MergeConflict is returned.
+// A task removed in one TaskList and modified in the other one is kept with the modifications and reported as a MergeConflict with zero Field.
+func Merge(base, ours, theirs TaskList) (TaskList, []*MergeConflict) {
+	oursMatch := matchTasks(base, ours)
+	theirsMatch := matchTasks(base, theirs)
+
+	// index of base task for each task in ours and theirs
+	oursBase := reverseMatch(oursMatch, len(ours))
+	theirsBase := reverseMatch(theirsMatch, len(theirs))
+
+	// tasks added in both TaskLists are the same task if they have the same "id:" tag or the same todo text
+	addedTheirs := make(map[int]int) // index in ours -> index in theirs
+	addedOurs := make(map[int]bool)  // index in theirs
+	for i := range ours {
+		if !ours[i].IsTask() || oursBase[i] >= 0 {
+			continue
+		}
+		for j := range theirs {
+			if theirs[j].IsTask() && theirsBase[j] < 0 && !addedOurs[j] && isSameNewTask(&ours[i], &theirs[j]) {
+				addedTheirs[i] = j
+				addedOurs[j] = true
+				break
+			}
+		}
+	}
+
+	var (
+		merged    TaskList
+		conflicts []*MergeConflict
+	)
+	for i := range ours {
+		task := &ours[i]
+		switch b := oursBase[i]; {
+		case !task.IsTask():
+			merged = append(merged, *task)
+		case b >= 0 && theirsMatch[b] >= 0:
+			result, cs := mergeTask(&base[b], task, &theirs[theirsMatch[b]])
+			merged = append(merged, result)
+			conflicts = append(conflicts, cs...)
+		case b >= 0:
+			// removed in theirs
+			if !isSameTask(&base[b], task) {
+				merged = append(merged, copyTask(task))
+				conflicts = append(conflicts, &MergeConflict{ID: task.ID})
+			}
+		default:
+			// added in ours
+			if j, found := addedTheirs[i]; found {
+				result, cs := mergeTask(&Task{}, task, &theirs[j])
+				merged = append(merged, result)
+				conflicts = append(conflicts, cs...)
+			} else {
+				merged = append(merged, copyTask(task))
+			}
+		}
+	}
+
+	for j := range theirs {
+		task := &theirs[j]
+		if !task.IsTask() || addedOurs[j] {
+			continue
+		}
+		if b := theirsBase[j]; b < 0 {
+			// added in theirs
+			added := copyTask(task)
+			merged.AddTask(&added)
+		} else if oursMatch[b] < 0 && !isSameTask(&base[b], task) {
+			// removed in ours, but modified in theirs
+			added := copyTask(task)
+			merged.AddTask(&added)
+			conflicts = append(conflicts, &MergeConflict{ID: added.ID})
+		}
+	}
+
+	if merged == nil {
+		merged = TaskList{}
+	}
+	return merged, conflicts
+}
+
+// mergeTask merges the changes of two versions of the base task, and returns the merged task and all conflicts.
+func mergeTask(base, ours, theirs *Task) (Task, []*MergeConflict) {
+	var conflicts []*MergeConflict
+	result := copyTask(ours)
+
+	// merge3 returns the merged value of a field, and adds a conflict if the field was changed differently
+	merge3 := func(field TaskSegmentType, tag, b, o, t string) string {
+		switch {
+		case o == t || t == b:
+			return o
+		case o == b:
+			return t
+		}
+		conflicts = append(conflicts, &MergeConflict{ID: ours.ID, Field: field, Tag: tag, Base: b, Ours: o, Theirs: t})
+		return o
+	}
+
+	if merge3(SegmentIsCompleted, emptyStr, completedValue(base), completedValue(ours), completedValue(theirs)) != completedValue(ours) {
+		result.Completed, result.CompletedDate, result.completedTime = theirs.Completed, theirs.CompletedDate, theirs.completedTime
+		if !result.Completed {
+			result.CompletedDate, result.completedTime = time.Time{}, false
+		}
+	}
+	result.Priority = merge3(SegmentPriority, emptyStr, base.Priority, ours.Priority, theirs.Priority)
+	if merge3(SegmentCreatedDate, emptyStr, dateValue(base.CreatedDate), dateValue(ours.CreatedDate), dateValue(theirs.CreatedDate)) != dateValue(ours.CreatedDate) {
+		result.CreatedDate = theirs.CreatedDate
+	}
+	result.Todo = merge3(SegmentTodoText, emptyStr, base.Todo, ours.Todo, theirs.Todo)
+	result.Contexts = mergeSet(base.Contexts, ours.Contexts, theirs.Contexts)
+	result.Projects = mergeSet(base.Projects, ours.Projects, theirs.Projects)
+
+	keys := make(map[string]bool)
+	for _, task := range []*Task{base, ours, theirs} {
+		for key := range task.AdditionalTags {
+			keys[key] = true
+		}
+	}
+	tags := make(map[string]string, len(keys))
+	for _, key := range sortedStrings(stringKeys(keys)) {
+		if value := merge3(SegmentTag, key, base.AdditionalTags[key], ours.AdditionalTags[key], theirs.AdditionalTags[key]); isNotEmpty(value) {
+			tags[key] = value
+		}
+	}
+	if len(tags) > 0 || result.AdditionalTags != nil {
+		result.AdditionalTags = tags
+	}
+
+	if merge3(SegmentDueDate, emptyStr, dueDateValue(base), dueDateValue(ours), dueDateValue(theirs)) != dueDateValue(ours) {
+		result.DueDate, result.dueTime = theirs.DueDate, theirs.dueTime
+	}
+	if merge3(SegmentThresholdDate, emptyStr, dateValue(base.ThresholdDate), dateValue(ours.ThresholdDate), dateValue(theirs.ThresholdDate)) != dateValue(ours.ThresholdDate) {
+		result.ThresholdDate = theirs.ThresholdDate
+	}
+	return result, conflicts
+}
+
+// mergeSet merges two versions of the base set: elements added in either version are added, and elements removed in either version are removed.
+func mergeSet(base, ours, theirs []string) []string {
+	if ours == nil && theirs == nil {
+		return nil
+	}
+	inBase, inOurs, inTheirs := stringSet(base), stringSet(ours), stringSet(theirs)
+	result := make([]string, 0, len(ours)+len(theirs))
+	for _, s := range ours {
+		if !inBase[s] || inTheirs[s] {
+			result = append(result, s)
+		}
+	}
+	for _, s := range theirs {
+		if !inBase[s] && !inOurs[s] {
+			result = append(result, s)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// matchTasks pairs tasks of TaskList a with tasks of TaskList b by identity, and returns the index of the matched task in b for each task in a, or -1 if not matched.
+//
+// Tasks with the same "id:" tag are matched first, tasks with different "id:" tags are never matched.
+// Then tasks with the same todo text are matched in order, and at last the remaining tasks with the most similar todo text.
+func matchTasks(a, b TaskList) []int {
+	match := make([]int, len(a))
+	matched := make([]bool, len(b))
+	for i := range match {
+		match[i] = -1
+	}
+	candidates := func(fn func(ta, tb *Task) bool) {
+		for i := range a {
+			if match[i] >= 0 || !a[i].IsTask() {
+				continue
+			}
+			for j := range b {
+				if !matched[j] && b[j].IsTask() && fn(&a[i], &b[j]) {
+					match[i], matched[j] = j, true
+					break
+				}
+			}
+		}
+	}
+	candidates(func(ta, tb *Task) bool {
+		return ta.HasUID() && ta.UID() == tb.UID()
+	})
+	candidates(func(ta, tb *Task) bool {
+		return canMatchTasks(ta, tb) && ta.Todo == tb.Todo
+	})
+
+	type pair struct {
+		i, j  int
+		score float64
+	}
+	var pairs []pair
+	for i := range a {
+		if match[i] >= 0 || !a[i].IsTask() {
+			continue
+		}
+		for j := range b {
+			if !matched[j] && b[j].IsTask() && canMatchTasks(&a[i], &b[j]) {
+				if score := textSimilarity(a[i].Todo, b[j].Todo); score >= minTaskSimilarity {
+					pairs = append(pairs, pair{i, j, score})
+				}
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(x, y int) bool {
+		return pairs[x].score > pairs[y].score
+	})
+	for _, p := range pairs {
+		if match[p.i] < 0 && !matched[p.j] {
+			match[p.i], matched[p.j] = p.j, true
+		}
+	}
+	return match
+}
+
+// canMatchTasks returns false if both tasks have different "id:" tags.
+func canMatchTasks(a, b *Task) bool {
+	return !a.HasUID() || !b.HasUID() || a.UID() == b.UID()
+}
+
+// textSimilarity returns the Jaccard similarity of the words in both texts, ignoring case.
+func textSimilarity(a, b string) float64 {
+	wa, wb := stringSet(strings.Fields(strings.ToLower(a))), stringSet(strings.Fields(strings.ToLower(b)))
+	if len(wa) == 0 && len(wb) == 0 {
+		return 1
+	}
+	common := 0
+	for w := range wa {
+		if wb[w] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(wa)+len(wb)-common)
+}
+
+// reverseMatch returns the index in the first TaskList for each index of the second TaskList of the given matches, or -1 if not matched.
+func reverseMatch(match []int, n int) []int {
+	reverse := make([]int, n)
+	for j := range reverse {
+		reverse[j] = -1
+	}
+	for i, j := range match {
+		if j >= 0 {
+			reverse[j] = i
+		}
+	}
+	return reverse
+}
+
+// isSameTask returns true if both tasks have the same content.
+func isSameTask(a, b *Task) bool {
+	return a.canonical() == b.canonical()
+}
+
+// isSameNewTask returns true if both tasks added in different TaskLists are the same task.
+func isSameNewTask(a, b *Task) bool {
+	if a.HasUID() {
+		return a.UID() == b.UID()
+	}
+	return canMatchTasks(a, b) && a.Todo == b.Todo
+}
+
+// canonical returns the task string in the canonical todo.txt format.
+func (task *Task) canonical() string {
+	return defaultParser().canonicalString(task)
+}
+
+// copyTask returns a copy of the task, which doesn't share projects, contexts and tags with the given task.
+func copyTask(task *Task) Task {
+	result := *task
+	if task.Projects != nil {
+		result.Projects = append([]string{}, task.Projects...)
+	}
+	if task.Contexts != nil {
+		result.Contexts = append([]string{}, task.Contexts...)
+	}
+	if task.AdditionalTags != nil {
+		result.AdditionalTags = make(map[string]string, len(task.AdditionalTags))
+		for k, v := range task.AdditionalTags {
+			result.AdditionalTags[k] = v
+		}
+	}
+	return result
+}
+
+// completedValue returns the completion mark and completed date of the task as string.
+func completedValue(task *Task) string {
+	if !task.Completed {
+		return emptyStr
+	}
+	return strings.TrimSpace("x " + completedDateValue(task))
+}
+
+// dateValue returns the date as string in todo.txt format, or empty string for zero time.
+func dateValue(date time.Time) string {
+	if date.IsZero() {
+		return emptyStr
+	}
+	return date.Format(DateLayout)
+}
+
+// stringKeys returns the keys of the set.
+func stringKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	return keys
+}
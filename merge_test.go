@@ -0,0 +1,153 @@
+package todotxt
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	load := func(s string) TaskList {
+		tasklist, err := LoadFromReader(strings.NewReader(s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tasklist
+	}
+	base := load(`(A) 2020-01-01 Call Mom @phone
+Buy milk +Groceries
+Pay rent due:2020-02-01
+Write report id:r1
+Clean garage
+`)
+	ours := load(`(B) 2020-01-01 Call Mom @phone
+x 2020-01-10 Buy milk +Groceries
+Pay the rent due:2020-02-01
+Write the monthly report id:r1 @work
+Read book
+`)
+	theirs := load(`(A) 2020-01-01 Call Mom @phone @home
+Buy milk +Groceries +Shop
+Pay rent due:2020-02-05
+Write report id:r1 owner:bob
+Clean garage due:2020-03-01
+Watch movie
+`)
+
+	merged, conflicts := Merge(base, ours, theirs)
+	testExpected = `(B) 2020-01-01 Call Mom @home @phone
+x 2020-01-10 Buy milk +Groceries +Shop
+Pay the rent due:2020-02-05
+Write the monthly report @work id:r1 owner:bob
+Read book
+Clean garage due:2020-03-01
+Watch movie
+`
+	testGot = merged.String()
+	if testGot != testExpected {
+		t.Errorf("Expected merged TaskList to be [%s], but got [%s]", testExpected, testGot)
+	}
+	testExpected = 1
+	testGot = len(conflicts)
+	if testGot != testExpected {
+		t.Fatalf("Expected %d conflicts, but got %d", testExpected, testGot)
+	}
+	testExpected = MergeConflict{ID: 6}
+	testGot = *conflicts[0]
+	if testGot != testExpected {
+		t.Errorf("Expected conflict to be %v, but got %v", testExpected, testGot)
+	}
+	testExpected = 7
+	testGot = merged[6].ID
+	if testGot != testExpected {
+		t.Errorf("Expected added task to have ID %d, but got %d", testExpected, testGot)
+	}
+
+	// field conflicts keep our value
+	theirs = load(`(C) 2020-01-01 Call Mom @phone
+Buy milk +Groceries
+Pay rent due:2020-02-05
+Write report id:r1
+Clean garage
+x 2020-01-09 Read book
+`)
+	ours = load(`(B) 2020-01-01 Call Mom @phone
+Buy milk +Groceries
+Pay rent due:2020-02-03
+Write report id:r1
+Clean garage
+Read book
+`)
+	merged, conflicts = Merge(base, ours, theirs)
+	testExpected = strings.Replace(ours.String(), "Read book", "x 2020-01-09 Read book", 1)
+	testGot = merged.String()
+	if testGot != testExpected {
+		t.Errorf("Expected merged TaskList to be [%s], but got [%s]", testExpected, testGot)
+	}
+	expectedConflicts := []MergeConflict{
+		{ID: 1, Field: SegmentPriority, Base: "A", Ours: "B", Theirs: "C"},
+		{ID: 3, Field: SegmentDueDate, Base: "2020-02-01", Ours: "2020-02-03", Theirs: "2020-02-05"},
+	}
+	testExpected = len(expectedConflicts)
+	testGot = len(conflicts)
+	if testGot != testExpected {
+		t.Fatalf("Expected %d conflicts, but got %d", testExpected, testGot)
+	}
+	for i, c := range conflicts {
+		testExpected = expectedConflicts[i]
+		testGot = *c
+		if testGot != testExpected {
+			t.Errorf("(%d) Expected conflict to be %v, but got %v", i, testExpected, testGot)
+		}
+	}
+
+	// tag conflicts
+	ours = load("Write report id:r1 owner:alice\n")
+	theirs = load("Write report id:r1 owner:bob\n")
+	_, conflicts = Merge(load("Write report id:r1\n"), ours, theirs)
+	testExpected = 1
+	testGot = len(conflicts)
+	if testGot != testExpected {
+		t.Fatalf("Expected %d conflicts, but got %d", testExpected, testGot)
+	}
+	testExpected = MergeConflict{ID: 1, Field: SegmentTag, Tag: "owner", Ours: "alice", Theirs: "bob"}
+	testGot = *conflicts[0]
+	if testGot != testExpected {
+		t.Errorf("Expected conflict to be %v, but got %v", testExpected, testGot)
+	}
+
+	// unchanged and empty lists
+	merged, conflicts = Merge(base, base, base)
+	testExpected = base.String()
+	testGot = merged.String()
+	if testGot != testExpected || len(conflicts) != 0 {
+		t.Errorf("Expected merged TaskList to be [%s] without conflicts, but got [%s] with %d conflicts", testExpected, testGot, len(conflicts))
+	}
+	if merged[0].Contexts[0] = "changed"; base[0].Contexts[0] == "changed" {
+		t.Errorf("Expected merged TaskList not to share contexts with input")
+	}
+	merged, conflicts = Merge(nil, nil, nil)
+	if merged == nil || len(merged) != 0 || len(conflicts) != 0 {
+		t.Errorf("Expected empty merged TaskList, but got %v and %v", merged, conflicts)
+	}
+}
+
+func TestMatchTasks(t *testing.T) {
+	a := TaskList{
+		{Todo: "Call Mom about the party"},
+		{Todo: "Write report", AdditionalTags: map[string]string{"id": "1"}},
+		{Todo: "Something else"},
+		{Kind: LineComment},
+	}
+	b := TaskList{
+		{Todo: "Write report", AdditionalTags: map[string]string{"id": "2"}},
+		{Todo: "Call mom about party"},
+		{Todo: "Write final report", AdditionalTags: map[string]string{"id": "1"}},
+		{Kind: LineComment},
+	}
+	testExpected = "[1 2 -1 -1]"
+	testGot = fmt.Sprint(matchTasks(a, b))
+	if testGot != testExpected {
+		t.Errorf("Expected matches to be %v, but got %v", testExpected, testGot)
+	}
+}
@@ -0,0 +1,73 @@
+package todotxt
+
+import "time"
+
+// Options represents the settings for parsing, loading and formatting tasks.
+// See the package-level variables with the same names for details of each option.
+type Options struct {
+	IgnoreComments          bool           // Ignore lines starting with "#".
+	RemoveCompletedPriority bool           // Discard priority of completed tasks when formatting.
+	PreserveFormat          bool           // Keep original token order and spacing when formatting.
+	PreserveComments        bool           // Keep comment and blank lines when loading.
+	PreserveIndent          bool           // Write leading whitespace of parsed tasks in TaskList.
+	LenientLoading          bool           // Keep malformed lines and collect all errors when loading.
+	RelativeDates           bool           // Resolve relative dates like "due:tomorrow" when parsing.
+	RewriteRelativeDates    bool           // Rewrite relative dates as absolute dates when formatting with PreserveFormat.
+	DateLayout              string         // Layout for parsing and formatting dates.
+	Location                *time.Location // Location for parsing dates and calendar days of parsed and new tasks.
+	Clock                   Clock          // Clock for time-relative methods of parsed and new tasks, SystemClock is used if it's nil.
+}
+
+// DefaultOptions returns Options with the current values of the package-level variables, and no Clock.
+func DefaultOptions() Options {
+	return Options{
+		IgnoreComments:          IgnoreComments,
+		RemoveCompletedPriority: RemoveCompletedPriority,
+		PreserveFormat:          PreserveFormat,
+		PreserveComments:        PreserveComments,
+		PreserveIndent:          PreserveIndent,
+		LenientLoading:          LenientLoading,
+		RelativeDates:           RelativeDates,
+		RewriteRelativeDates:    RewriteRelativeDates,
+		DateLayout:              DateLayout,
+		Location:                Location,
+	}
+}
+
+// Parser parses, loads and formats tasks with its own Options instead of the package-level variables.
+// A Parser is safe for concurrent use by multiple goroutines.
+type Parser struct {
+	opts Options
+}
+
+// NewParser creates a new Parser with the given Options.
+// If Options.DateLayout is empty or Options.Location is nil, the current value of DateLayout or Location is used.
+func NewParser(opts Options) *Parser {
+	if isEmpty(opts.DateLayout) {
+		opts.DateLayout = DateLayout
+	}
+	if opts.Location == nil {
+		opts.Location = Location
+	}
+	return &Parser{opts: opts}
+}
+
+// defaultParser returns a Parser with the current values of the package-level variables.
+func defaultParser() *Parser {
+	return &Parser{opts: DefaultOptions()}
+}
+
+// Options returns the Options of the parser.
+func (p *Parser) Options() Options {
+	return p.opts
+}
+
+// parseTime parses the date string with the date layout of the parser.
+func (p *Parser) parseTime(s string) (time.Time, error) {
+	return time.ParseInLocation(p.opts.DateLayout, s, p.location())
+}
+
+// formatTime formats the date with the date layout of the parser.
+func (p *Parser) formatTime(t time.Time) string {
+	return t.Format(p.opts.DateLayout)
+}
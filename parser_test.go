@@ -0,0 +1,144 @@
+package todotxt
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestDefaultOptions(t *testing.T) {
+	opts := DefaultOptions()
+	if opts.IgnoreComments != IgnoreComments || opts.RemoveCompletedPriority != RemoveCompletedPriority ||
+		opts.PreserveFormat != PreserveFormat || opts.PreserveComments != PreserveComments || opts.PreserveIndent != PreserveIndent ||
+		opts.LenientLoading != LenientLoading || opts.RelativeDates != RelativeDates ||
+		opts.RewriteRelativeDates != RewriteRelativeDates || opts.DateLayout != DateLayout || opts.Location != Location {
+		t.Errorf("Expected default options to be the package-level variables, but got %+v", opts)
+	}
+
+	testExpected = DateLayout
+	testGot = NewParser(Options{}).Options().DateLayout
+	if testGot != testExpected {
+		t.Errorf("Expected parser to have date layout [%s], but got [%s]", testExpected, testGot)
+	}
+}
+
+func TestParserParseFormat(t *testing.T) {
+	parser := NewParser(Options{
+		RemoveCompletedPriority: true,
+		DateLayout:              "2006/01/02",
+	})
+
+	task, err := parser.Parse("x (A) Call Mom @Phone +Family due:2014/01/12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testExpected = "2014-01-12"
+	testGot = task.DueDate.Format(DateLayout)
+	if testGot != testExpected {
+		t.Errorf("Expected Task to have due date [%s], but got [%s]", testExpected, testGot)
+	}
+	testExpected = "x Call Mom @Phone +Family due:2014/01/12"
+	testGot = parser.Format(task)
+	if testGot != testExpected {
+		t.Errorf("Expected Task to be [%s], but got [%s]", testExpected, testGot)
+	}
+	testExpected = "x (A) Call Mom @Phone +Family due:2014-01-12"
+	testGot = task.String()
+	if testGot != testExpected {
+		t.Errorf("Expected Task to be [%s], but got [%s]", testExpected, testGot)
+	}
+
+	segs := parser.Segments(task)
+	testExpected = "due:2014/01/12"
+	testGot = segs[len(segs)-1].Display
+	if testGot != testExpected {
+		t.Errorf("Expected last segment to be [%s], but got [%s]", testExpected, testGot)
+	}
+
+	if _, err := parser.Parse("Call Mom due:2014-01-12"); err == nil {
+		t.Errorf("Expected Parse to fail for date in other layout, but it didn't")
+	}
+
+	preserved := NewParser(Options{PreserveFormat: true})
+	task, err = preserved.Parse("(B) Call +Family Mom @Phone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	task.Priority = "A"
+	testExpected = "(A) Call +Family Mom @Phone"
+	testGot = preserved.Format(task)
+	if testGot != testExpected {
+		t.Errorf("Expected Task to be [%s], but got [%s]", testExpected, testGot)
+	}
+}
+
+func TestParserLoadWrite(t *testing.T) {
+	input := "# Home\n(A) Call Mom @Phone +Family\n\nx 2014-01-02 Pick up milk due:2014-02-32\n"
+
+	parser := NewParser(Options{
+		IgnoreComments:   true,
+		PreserveComments: true,
+		LenientLoading:   true,
+	})
+	tasklist, err := parser.Load(strings.NewReader(input))
+	if _, ok := err.(ParseErrors); !ok {
+		t.Errorf("Expected Load to fail with ParseErrors, but got: %v", err)
+	}
+	testExpected = 4
+	testGot = len(tasklist)
+	if testGot != testExpected {
+		t.Errorf("Expected TaskList to contain %d entries, but got %d", testExpected, testGot)
+	}
+
+	var buf bytes.Buffer
+	if err := parser.Write(&buf, tasklist); err != nil {
+		t.Fatal(err)
+	}
+	testExpected = input
+	testGot = buf.String()
+	if testGot != testExpected {
+		t.Errorf("Expected TaskList to be [%s], but got [%s]", testExpected, testGot)
+	}
+
+	strict := NewParser(Options{IgnoreComments: false})
+	if tasklist, err := strict.Load(strings.NewReader(input)); tasklist != nil || err == nil {
+		t.Errorf("Expected Load to fail, but got TaskList back: [%s]", tasklist)
+	}
+	if tasklist, err := strict.Load(strings.NewReader("# Home\n(A) Call Mom @Phone +Family\n")); err != nil {
+		t.Error(err)
+	} else if len(tasklist) != 2 || tasklist[0].Todo != "# Home" {
+		t.Errorf("Expected comment to be parsed as task, but got TaskList: [%s]", tasklist)
+	}
+}
+
+func TestParserConcurrentUse(t *testing.T) {
+	parsers := []*Parser{
+		NewParser(Options{RemoveCompletedPriority: true}),
+		NewParser(Options{RemoveCompletedPriority: false}),
+	}
+	expected := []string{
+		"x 2014-01-02 Call Mom @Phone",
+		"x 2014-01-02 (A) Call Mom @Phone",
+	}
+
+	var wg sync.WaitGroup
+	for i := range parsers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				task, err := parsers[i].Parse("x 2014-01-02 (A) Call Mom @Phone")
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if got := parsers[i].Format(task); got != expected[i] {
+					t.Errorf("Expected Task to be [%s], but got [%s]", expected[i], got)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
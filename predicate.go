@@ -0,0 +1,167 @@
+package todotxt
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// PredicateExpr represents a predicate which can be combined with And(), Or(), Xor() and Not(), and printed as a predicate tree,
+// e.g. "(project:work AND NOT @phone)" for debugging saved views. Use its Match method as Predicate for filtering.
+//
+// Predicate implements PredicateExpr, use Named() to give it a readable name. Only Named predicates and the ones built by ParseQueryExpr
+// print their arguments, the builders like FilterByProject("work") print as "FilterByProject.func1", so wrap them with
+// Named("+work", FilterByProject("work")) to get a readable tree.
+type PredicateExpr interface {
+	Match(t Task) bool
+	String() string
+}
+
+// Match returns p(t), so Predicate implements PredicateExpr.
+func (p Predicate) Match(t Task) bool {
+	return p(t)
+}
+
+// String returns the function name of the predicate, e.g. "FilterCompleted" or "FilterByProject.func1" for closures.
+// The arguments of closures are not part of the name, use Named() to print them.
+func (p Predicate) String() string {
+	if p == nil {
+		return "nil"
+	}
+	name := runtime.FuncForPC(reflect.ValueOf(p).Pointer()).Name()
+	name = name[strings.LastIndex(name, "/")+1:]
+	return name[strings.Index(name, ".")+1:]
+}
+
+// namedExpr is a predicate with a name.
+type namedExpr struct {
+	name      string
+	predicate Predicate
+}
+
+// Named returns a PredicateExpr of the predicate, which is printed as the given name.
+func Named(name string, predicate Predicate) PredicateExpr {
+	return namedExpr{name: name, predicate: predicate}
+}
+
+func (e namedExpr) Match(t Task) bool {
+	return e.predicate(t)
+}
+
+func (e namedExpr) String() string {
+	return e.name
+}
+
+// andExpr matches if all predicates match.
+type andExpr []PredicateExpr
+
+// And returns a PredicateExpr matching tasks matched by all the given predicates. It stops at the first predicate not matching.
+// It matches all tasks if no predicate is given.
+func And(exprs ...PredicateExpr) PredicateExpr {
+	return andExpr(exprs)
+}
+
+func (e andExpr) Match(t Task) bool {
+	for _, expr := range e {
+		if !expr.Match(t) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e andExpr) String() string {
+	return joinExprs(e, " AND ", "TRUE")
+}
+
+// orExpr matches if any predicate matches.
+type orExpr []PredicateExpr
+
+// Or returns a PredicateExpr matching tasks matched by any of the given predicates. It stops at the first predicate matching.
+// It matches no task if no predicate is given.
+func Or(exprs ...PredicateExpr) PredicateExpr {
+	return orExpr(exprs)
+}
+
+func (e orExpr) Match(t Task) bool {
+	for _, expr := range e {
+		if expr.Match(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e orExpr) String() string {
+	return joinExprs(e, " OR ", "FALSE")
+}
+
+// xorExpr matches if exactly one of both predicates matches.
+type xorExpr [2]PredicateExpr
+
+// Xor returns a PredicateExpr matching tasks matched by exactly one of both predicates.
+func Xor(a, b PredicateExpr) PredicateExpr {
+	return xorExpr{a, b}
+}
+
+func (e xorExpr) Match(t Task) bool {
+	return e[0].Match(t) != e[1].Match(t)
+}
+
+func (e xorExpr) String() string {
+	return joinExprs(e[:], " XOR ", emptyStr)
+}
+
+// notExpr matches if the predicate doesn't match.
+type notExpr struct {
+	expr PredicateExpr
+}
+
+// Not returns a PredicateExpr matching tasks not matched by the given predicate.
+func Not(expr PredicateExpr) PredicateExpr {
+	return notExpr{expr: expr}
+}
+
+func (e notExpr) Match(t Task) bool {
+	return !e.expr.Match(t)
+}
+
+func (e notExpr) String() string {
+	return "NOT " + e.expr.String()
+}
+
+// joinExprs returns the predicates joined by the operator in parentheses, or the given string for no predicates.
+func joinExprs(exprs []PredicateExpr, op, none string) string {
+	switch len(exprs) {
+	case 0:
+		return none
+	case 1:
+		return exprs[0].String()
+	}
+	strs := make([]string, len(exprs))
+	for i, expr := range exprs {
+		strs[i] = expr.String()
+	}
+	return "(" + strings.Join(strs, op) + ")"
+}
+
+// Any returns a PredicateExpr for tasks matched by any of the given predicates, like TaskList.Filter() combines them.
+// It stops at the first predicate matching, and matches no task if no predicate is given. See Or() for combining PredicateExpr.
+func Any(predicates ...Predicate) PredicateExpr {
+	return Or(predicateExprs(predicates)...)
+}
+
+// All returns a PredicateExpr for tasks matched by all the given predicates, like TaskList.FilterAll() combines them.
+// It stops at the first predicate not matching, and matches all tasks if no predicate is given. See And() for combining PredicateExpr.
+func All(predicates ...Predicate) PredicateExpr {
+	return And(predicateExprs(predicates)...)
+}
+
+// predicateExprs returns the predicates as PredicateExpr.
+func predicateExprs(predicates []Predicate) []PredicateExpr {
+	exprs := make([]PredicateExpr, len(predicates))
+	for i, p := range predicates {
+		exprs[i] = p
+	}
+	return exprs
+}
@@ -0,0 +1,111 @@
+package todotxt
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestPredicateExpr(t *testing.T) {
+	task, _ := ParseTask("(A) Call Mom @phone +Family")
+	calls := 0
+	counted := func(result bool) Predicate {
+		return func(Task) bool {
+			calls++
+			return result
+		}
+	}
+	yes, no := Named("yes", counted(true)), Named("no", counted(false))
+
+	for _, tc := range []struct {
+		expr     PredicateExpr
+		str      string
+		expected bool
+		calls    int
+	}{
+		{And(yes, no, yes), "(yes AND no AND yes)", false, 2},
+		{And(yes, yes), "(yes AND yes)", true, 2},
+		{And(), "TRUE", true, 0},
+		{Or(no, yes, no), "(no OR yes OR no)", true, 2},
+		{Or(no), "no", false, 1},
+		{Or(), "FALSE", false, 0},
+		{Xor(yes, no), "(yes XOR no)", true, 2},
+		{Xor(yes, yes), "(yes XOR yes)", false, 2},
+		{Not(And(yes, Or(no, Not(yes)))), "NOT (yes AND (no OR NOT yes))", true, 3},
+		{And(Predicate(FilterHasPriority), FilterByContext("phone")), "(FilterHasPriority AND FilterByContext.func1)", true, 0},
+		{And(Predicate(FilterHasPriority), Named("@phone", FilterByContext("phone"))), "(FilterHasPriority AND @phone)", true, 0},
+	} {
+		calls = 0
+		testExpected = tc.str
+		testGot = tc.expr.String()
+		if testGot != testExpected {
+			t.Errorf("Expected PredicateExpr to be printed as [%s], but got [%s]", testExpected, testGot)
+		}
+		testExpected = tc.expected
+		testGot = tc.expr.Match(*task)
+		if testGot != testExpected {
+			t.Errorf("Expected %s to be %v, but got %v", tc.expr, testExpected, testGot)
+		}
+		testExpected = tc.calls
+		testGot = calls
+		if testGot != testExpected {
+			t.Errorf("Expected %s to call %d predicates, but got %d", tc.expr, testExpected, testGot)
+		}
+	}
+
+	testExpected = "nil"
+	testGot = Predicate(nil).String()
+	if testGot != testExpected {
+		t.Errorf("Expected nil Predicate to be printed as [%s], but got [%s]", testExpected, testGot)
+	}
+}
+
+func TestAnyAll(t *testing.T) {
+	if err := testTasklist.LoadFromPath(testInputTasklist); err != nil {
+		t.Fatal(err)
+	}
+
+	testExpected = len(testTasklist.Filter(FilterCompleted, FilterHasDueDate))
+	testGot = len(testTasklist.Filter(Any(FilterCompleted, FilterHasDueDate).Match))
+	if testGot != testExpected {
+		t.Errorf("Expected TaskList to contain %d tasks, but got %d", testExpected, testGot)
+	}
+	testExpected = len(testTasklist.FilterAll(FilterCompleted, FilterHasDueDate))
+	testGot = len(testTasklist.Filter(All(FilterCompleted, FilterHasDueDate).Match))
+	if testGot != testExpected {
+		t.Errorf("Expected TaskList to contain %d tasks, but got %d", testExpected, testGot)
+	}
+	if len(testTasklist.Filter(Any().Match)) != 0 || len(testTasklist.Filter(All().Match)) != len(testTasklist) {
+		t.Errorf("Expected Any() to match no task and All() to match all tasks")
+	}
+
+	// combined predicates are printed as predicate tree
+	testExpected = "(FilterCompleted OR FilterHasDueDate) (FilterCompleted AND FilterHasDueDate) FALSE TRUE"
+	testGot = fmt.Sprint(Any(FilterCompleted, FilterHasDueDate), " ", All(FilterCompleted, FilterHasDueDate), " ", Any(), " ", All())
+	if testGot != testExpected {
+		t.Errorf("Expected predicates to be printed as [%s], but got [%s]", testExpected, testGot)
+	}
+
+	// PredicateExpr can be used for filtering
+	expr := And(Predicate(FilterCompleted), Not(Predicate(FilterHasDueDate)))
+	testExpected = len(testTasklist.FilterAll(FilterCompleted, FilterNot(FilterHasDueDate)))
+	testGot = len(testTasklist.Filter(expr.Match))
+	if testGot != testExpected {
+		t.Errorf("Expected TaskList to contain %d tasks, but got %d", testExpected, testGot)
+	}
+}
+
+func TestParseQueryExpr(t *testing.T) {
+	expr, err := ParseQueryExpr(`project:work and (priority<=B or due<today+3d) and not @phone /x y/i "a b" or c`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testExpected = `((project:work AND (priority<=B OR due<today+3d) AND NOT @phone AND /x y/i AND "a b") OR c)`
+	testGot = expr.String()
+	if testGot != testExpected {
+		t.Errorf("Expected query to be printed as [%s], but got [%s]", testExpected, testGot)
+	}
+	if _, err := ParseQueryExpr("(a"); err == nil || !strings.Contains(err.Error(), "column 3") {
+		t.Errorf("Expected query error at column 3, but got: %v", err)
+	}
+}
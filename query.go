@@ -0,0 +1,406 @@
+package todotxt
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	queryCompareRx = regexp.MustCompile(`^([A-Za-z_][\w-]*)(<=|>=|!=|=|<|>)(.*)$`) // Match comparison: 'priority<=B' or 'due<today+3d'
+	queryTagRx     = regexp.MustCompile(`^([^:\s]+):(.+)$`)                        // Match tag: 'project:work' or 'owner:bob'
+)
+
+// QueryError represents a syntax error in a query, with the position of the offending token.
+type QueryError struct {
+	Query  string // Query text.
+	Column int    // Byte column of the offending token in the query, starting from 1.
+	Msg    string // Description of the error.
+}
+
+// Error returns the error message with column.
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("query syntax error at column %d: %s", e.Column, e.Msg)
+}
+
+// queryTokenKind represents kind of a token in query.
+type queryTokenKind uint8
+
+// Kinds of tokens in query.
+const (
+	queryEOF queryTokenKind = iota
+	queryLParen
+	queryRParen
+	queryWord
+	queryString
+	queryRegex
+)
+
+// queryToken represents a token in query.
+type queryToken struct {
+	kind queryTokenKind
+	text string // Text of word, string or regex, without quotes and slashes.
+	raw  string // Raw text of the token in query.
+	pos  int    // Byte offset in query.
+}
+
+// ParseQuery parses the query text into a Predicate, for filtering tasks by queries typed by users, e.g.
+//
+//	project:work and (priority<=B or due<today+3d) and not @phone
+//
+// The query is a boolean expression of terms, combined by "and", "or", "not" and parentheses.
+// Terms next to each other without an operator are combined by "and". Supported terms are:
+//
+//	@context, +project               task has the context or project, same as context:name and project:name
+//	priority=A, priority<=B, ...     priority compared alphabetically, i.e. "A" < "B", tasks without priority never match
+//	due<today+3d, created>=2020-01-01, completed=yesterday, t<=today
+//	                                 date compared by calendar days, tasks without the date never match
+//	                                 date values: YYYY-MM-DD, or relative dates like today, fri, eom, today+3d, -1w, see ParseRelativeDate()
+//	key:value, key=value, key!=value tag value equals, key:* matches any task with the tag
+//	key<value, key>=value, ...       tag value compared numerically if both are numbers, or as strings otherwise
+//	is:completed, is:overdue, is:duetoday, is:actionable
+//	/regex/, /regex/i                todo text matches the regular expression, optionally ignoring case
+//	word, "quoted text"              todo text contains the text, ignoring case
+//
+// Relative dates are resolved when the Predicate is called, according to the Clock of each task.
+// Use ParseQueryExpr() to get the predicate tree of the query for printing.
+// Returns a *QueryError if the query is malformed.
+func ParseQuery(query string) (Predicate, error) {
+	expr, err := ParseQueryExpr(query)
+	if err != nil {
+		return nil, err
+	}
+	return expr.Match, nil
+}
+
+// ParseQueryExpr parses the query text into a PredicateExpr, which is printed as the normalized predicate tree of the query,
+// e.g. "(project:work AND (priority<=B OR due<today+3d) AND NOT @phone)". See ParseQuery() for the syntax of queries.
+func ParseQueryExpr(query string) (PredicateExpr, error) {
+	tokens, err := lexQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{query: query, tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != queryEOF {
+		return nil, p.errorAt(tok, "unexpected %q", p.tokenText(tok))
+	}
+	return expr, nil
+}
+
+// lexQuery splits the query into tokens.
+func lexQuery(query string) ([]queryToken, error) {
+	var tokens []queryToken
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case strings.IndexByte(whitespaces, c) >= 0:
+			i++
+		case c == '(':
+			tokens = append(tokens, queryToken{kind: queryLParen, text: "(", raw: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, queryToken{kind: queryRParen, text: ")", raw: ")", pos: i})
+			i++
+		case c == '"' || c == '/':
+			text, end, found := scanQuoted(query, i)
+			if !found {
+				return nil, &QueryError{Query: query, Column: i + 1, Msg: fmt.Sprintf("unterminated %c", c)}
+			}
+			kind := queryString
+			if c == '/' {
+				kind = queryRegex
+				if end < len(query) && query[end] == 'i' {
+					text = "(?i)" + text
+					end++
+				}
+			}
+			tokens = append(tokens, queryToken{kind: kind, text: text, raw: query[i:end], pos: i})
+			i = end
+		default:
+			end := i
+			for end < len(query) && strings.IndexByte(whitespaces+"()", query[end]) < 0 {
+				end++
+			}
+			tokens = append(tokens, queryToken{kind: queryWord, text: query[i:end], raw: query[i:end], pos: i})
+			i = end
+		}
+	}
+	return append(tokens, queryToken{kind: queryEOF, pos: len(query)}), nil
+}
+
+// scanQuoted returns the text between the quote character at start and the next unescaped one, and the position after the closing quote.
+func scanQuoted(s string, start int) (string, int, bool) {
+	quote := s[start]
+	var sb strings.Builder
+	for i := start + 1; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s) && s[i+1] == quote:
+			sb.WriteByte(quote)
+			i++
+		case s[i] == quote:
+			return sb.String(), i + 1, true
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return emptyStr, len(s), false
+}
+
+// queryParser parses the tokens of query into a Predicate by recursive descent.
+type queryParser struct {
+	query  string
+	tokens []queryToken
+	pos    int
+}
+
+// peek returns the current token.
+func (p *queryParser) peek() queryToken {
+	return p.tokens[p.pos]
+}
+
+// next returns the current token and advances to the next one.
+func (p *queryParser) next() queryToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != queryEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// isKeyword returns true if the token is the given keyword, ignoring case.
+func (p *queryParser) isKeyword(tok queryToken, keyword string) bool {
+	return tok.kind == queryWord && strings.EqualFold(tok.text, keyword)
+}
+
+// tokenText returns the text of the token for error messages.
+func (p *queryParser) tokenText(tok queryToken) string {
+	if tok.kind == queryEOF {
+		return "end of query"
+	}
+	return tok.text
+}
+
+// errorAt returns a *QueryError for the token.
+func (p *queryParser) errorAt(tok queryToken, format string, args ...interface{}) error {
+	return &QueryError{Query: p.query, Column: tok.pos + 1, Msg: fmt.Sprintf(format, args...)}
+}
+
+// parseOr parses: and-expression { "or" and-expression }
+func (p *queryParser) parseOr() (PredicateExpr, error) {
+	var exprs []PredicateExpr
+	for {
+		expr, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+		if !p.isKeyword(p.peek(), "or") {
+			break
+		}
+		p.next()
+	}
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+	return Or(exprs...), nil
+}
+
+// parseAnd parses: not-expression { ["and"] not-expression }
+func (p *queryParser) parseAnd() (PredicateExpr, error) {
+	var exprs []PredicateExpr
+	for {
+		expr, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+		tok := p.peek()
+		if tok.kind == queryEOF || tok.kind == queryRParen || p.isKeyword(tok, "or") {
+			break
+		}
+		if p.isKeyword(tok, "and") {
+			p.next()
+		}
+	}
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+	return And(exprs...), nil
+}
+
+// parseNot parses: "not" not-expression | "(" or-expression ")" | term
+func (p *queryParser) parseNot() (PredicateExpr, error) {
+	tok := p.next()
+	switch {
+	case p.isKeyword(tok, "not"):
+		expr, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return Not(expr), nil
+	case tok.kind == queryLParen:
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if end := p.next(); end.kind != queryRParen {
+			return nil, p.errorAt(end, "expected \")\", but got %q", p.tokenText(end))
+		}
+		return expr, nil
+	case tok.kind == queryString:
+		return Named(tok.raw, FilterTodoContains(tok.text)), nil
+	case tok.kind == queryRegex:
+		rx, err := regexp.Compile(tok.text)
+		if err != nil {
+			return nil, p.errorAt(tok, "invalid regular expression: %v", err)
+		}
+		return Named(tok.raw, FilterTodoMatches(rx)), nil
+	case tok.kind == queryWord && !p.isKeyword(tok, "and") && !p.isKeyword(tok, "or"):
+		pred, err := p.parseTerm(tok)
+		if err != nil {
+			return nil, err
+		}
+		return Named(tok.raw, pred), nil
+	}
+	return nil, p.errorAt(tok, "unexpected %q", p.tokenText(tok))
+}
+
+// parseTerm parses a word into a Predicate.
+func (p *queryParser) parseTerm(tok queryToken) (Predicate, error) {
+	word := tok.text
+	switch {
+	case len(word) > 1 && word[0] == '@':
+		return FilterByContext(word[1:]), nil
+	case len(word) > 1 && word[0] == '+':
+		return FilterByProject(word[1:]), nil
+	}
+
+	if match := queryCompareRx.FindStringSubmatch(word); match != nil {
+		key, op, value := match[1], match[2], match[3]
+		if isEmpty(value) {
+			return nil, p.errorAt(tok, "missing value for %q", match[1])
+		}
+		return p.parseComparison(tok, key, op, value)
+	}
+
+	if match := queryTagRx.FindStringSubmatch(word); match != nil {
+		key, value := match[1], match[2]
+		switch strings.ToLower(key) {
+		case "project":
+			return FilterByProject(value), nil
+		case "context":
+			return FilterByContext(value), nil
+		case "is":
+			return p.parseState(tok, strings.ToLower(value))
+		}
+		if value == "*" {
+			return FilterHasTag(key), nil
+		}
+		return p.parseComparison(tok, key, "=", value)
+	}
+
+	return FilterTodoContains(word), nil
+}
+
+// parseState parses the value of "is:" term into a Predicate.
+func (p *queryParser) parseState(tok queryToken, state string) (Predicate, error) {
+	switch state {
+	case "completed", "done":
+		return FilterCompleted, nil
+	case "overdue":
+		return FilterOverdue, nil
+	case "duetoday":
+		return FilterDueToday, nil
+	case "actionable":
+		return FilterActionable, nil
+	}
+	return nil, p.errorAt(tok, "unknown state %q", state)
+}
+
+// parseComparison parses the comparison of a field or tag with the value into a Predicate.
+func (p *queryParser) parseComparison(tok queryToken, key, op, value string) (Predicate, error) {
+	compare := func(c int) bool {
+		switch op {
+		case "<":
+			return c < 0
+		case "<=":
+			return c <= 0
+		case ">":
+			return c > 0
+		case ">=":
+			return c >= 0
+		case "!=":
+			return c != 0
+		}
+		return c == 0
+	}
+
+	switch strings.ToLower(key) {
+	case "priority", "pri":
+		priority := strings.ToUpper(value)
+		if !priorityTokenRx.MatchString("(" + priority + ")") {
+			return nil, p.errorAt(tok, "invalid priority %q", value)
+		}
+		return func(t Task) bool {
+			return t.HasPriority() && compare(strings.Compare(t.Priority, priority))
+		}, nil
+	case "due", "t", "threshold", "created", "completed":
+		resolve, err := parseQueryDate(value)
+		if err != nil {
+			return nil, p.errorAt(tok, "invalid date %q", value)
+		}
+		field := queryDateField(strings.ToLower(key))
+		return func(t Task) bool {
+			date := field(&t)
+			return !date.IsZero() && compare(daysBetween(resolve(t.now()), date))
+		}, nil
+	}
+
+	return func(t Task) bool {
+		tag, found := t.AdditionalTags[key]
+		return found && compare(compareTagValues(tag, value))
+	}, nil
+}
+
+// queryDateField returns the function to get the date field of a task for the key of query.
+func queryDateField(key string) func(t *Task) time.Time {
+	switch key {
+	case "t", "threshold":
+		return func(t *Task) time.Time { return t.ThresholdDate }
+	case "created":
+		return func(t *Task) time.Time { return t.CreatedDate }
+	case "completed":
+		return func(t *Task) time.Time { return t.CompletedDate }
+	}
+	return func(t *Task) time.Time { return t.DueDate }
+}
+
+// parseQueryDate parses an absolute or relative date of query, and returns the function to resolve the date for the current time.
+func parseQueryDate(value string) (func(now time.Time) time.Time, error) {
+	if date, err := parseTime(value); err == nil {
+		return func(time.Time) time.Time { return date }, nil
+	}
+	return relativeDate(value)
+}
+
+// compareTagValues compares tag values numerically if both are numbers, or as strings otherwise.
+func compareTagValues(a, b string) int {
+	if fa, err := strconv.ParseFloat(a, 64); err == nil {
+		if fb, err := strconv.ParseFloat(b, 64); err == nil {
+			switch {
+			case fa < fb:
+				return -1
+			case fa > fb:
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(a, b)
+}
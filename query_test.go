@@ -0,0 +1,128 @@
+package todotxt
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseQuery(t *testing.T) {
+	tasklist, err := LoadFromReader(strings.NewReader(`(A) Call Mom @phone +Family due:2020-01-02
+(B) Write report +work due:2020-01-10 est:3
+(C) Review code +work @office due:2020-01-03 est:12 owner:Bob
+x 2020-01-01 Buy milk @store
+Plan trip t:2020-02-01
+2019-12-01 Read book id:AB
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tasklist.SetClock(FixedClock(time.Date(2020, 1, 1, 18, 0, 0, 0, time.Local)))
+
+	for _, tc := range []struct {
+		query    string
+		expected []int
+	}{
+		{"project:work and (priority<=B or due<today+3d) and not @phone", []int{2, 3}},
+		{"+work", []int{2, 3}},
+		{"+WORK", []int{2, 3}},
+		{"@phone or @store", []int{1, 4}},
+		{"context:office", []int{3}},
+		{"priority<=B", []int{1, 2}},
+		{"priority>a", []int{2, 3}},
+		{"pri!=B", []int{1, 3}},
+		{"priority:C", []int{3}},
+		{"due<today+3d", []int{1, 3}},
+		{"due<=tomorrow", []int{1}},
+		{"due>=2020-01-03 due<2020-01-10", []int{3}},
+		{"due=+1w", nil},
+		{"due:today+9d", []int{2}},
+		{"completed=yesterday+1d", []int{4}},
+		{"created<today-1w", []int{6}},
+		{"t>today", []int{5}},
+		{"threshold>=2020-02-01", []int{5}},
+		{"est>5", []int{3}},
+		{"est<=3", []int{2}},
+		{"est:*", []int{2, 3}},
+		{"owner=Bob", []int{3}},
+		{"owner!=Bob", nil},
+		{"id:AB", []int{6}},
+		{"is:completed", []int{4}},
+		{"not is:done", []int{1, 2, 3, 5, 6}},
+		{"is:overdue", nil},
+		{"is:duetoday", nil},
+		{"not is:actionable", []int{5}},
+		{`/^(Call|Read) /`, []int{1, 6}},
+		{`/^call/i`, []int{1}},
+		{`/rev\/iew/`, nil},
+		{`"buy MILK"`, []int{4}},
+		{`"say \"hi\""`, nil},
+		{"book OR milk", []int{4, 6}},
+		{"NOT (+work or @phone) AND NOT is:completed", []int{5, 6}},
+		{"not not @phone", []int{1}},
+	} {
+		pred, err := ParseQuery(tc.query)
+		if err != nil {
+			t.Errorf("Expected query [%s] to be parsed, but got error: %v", tc.query, err)
+			continue
+		}
+		var ids []int
+		for _, task := range tasklist.Filter(pred) {
+			ids = append(ids, task.ID)
+		}
+		testExpected = fmt.Sprint(tc.expected)
+		testGot = fmt.Sprint(ids)
+		if testGot != testExpected {
+			t.Errorf("Expected query [%s] to filter tasks %v, but got %v", tc.query, testExpected, testGot)
+		}
+	}
+}
+
+func TestParseQueryError(t *testing.T) {
+	for _, tc := range []struct {
+		query  string
+		column int
+		msg    string
+	}{
+		{"", 1, `unexpected "end of query"`},
+		{"@phone and", 11, `unexpected "end of query"`},
+		{"(@phone or +work", 17, `expected ")", but got "end of query"`},
+		{"@phone)", 7, `unexpected ")"`},
+		{"or @phone", 1, `unexpected "or"`},
+		{"@phone and or +work", 12, `unexpected "or"`},
+		{"priority<=", 1, `missing value for "priority"`},
+		{"priority<=AB", 1, `invalid priority "AB"`},
+		{"+work due<soon", 7, `invalid date "soon"`},
+		{"due:today+3x", 1, `invalid date "today+3x"`},
+		{"due=+1w+2d", 1, `invalid date "+1w+2d"`},
+		{"is:blocked", 1, `unknown state "blocked"`},
+		{"/a(/", 1, "invalid regular expression: error parsing regexp: missing closing ): `a(`"},
+		{`@phone "milk`, 8, `unterminated "`},
+	} {
+		_, err := ParseQuery(tc.query)
+		var qerr *QueryError
+		if !errors.As(err, &qerr) {
+			t.Errorf("Expected query [%s] to fail with *QueryError, but got: %v", tc.query, err)
+			continue
+		}
+		testExpected = tc.column
+		testGot = qerr.Column
+		if testGot != testExpected {
+			t.Errorf("Expected query [%s] to fail at column %d, but got %d", tc.query, testExpected, testGot)
+		}
+		testExpected = tc.msg
+		testGot = qerr.Msg
+		if testGot != testExpected {
+			t.Errorf("Expected query [%s] to fail with [%s], but got [%s]", tc.query, testExpected, testGot)
+		}
+	}
+
+	_, err := ParseQuery("@phone and")
+	testExpected = `query syntax error at column 11: unexpected "end of query"`
+	testGot = err.Error()
+	if testGot != testExpected {
+		t.Errorf("Expected error to be [%s], but got [%s]", testExpected, testGot)
+	}
+}
@@ -0,0 +1,268 @@
+package todotxt
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Keys of additional tags set by quick-add.
+const (
+	EstimateTag = "est"  // Time estimate of the task, e.g. "est:2h".
+	TimeTag     = "time" // Time of day of the due date in 24-hour clock, e.g. "time:17:00".
+)
+
+var (
+	quickPriorityRx = regexp.MustCompile(`^(?:!([A-Za-z])|\(([A-Z])\))$`)              // Match priority: '!A', '!a' or '(A)'
+	quickTimeRx     = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)?$`)           // Match time of day: '5pm', '5:30pm' or '17:00'
+	quickEstimateRx = regexp.MustCompile(`^~(\d+(?:\.\d+)?[mhd])$`)                    // Match estimate: '~30m', '~2h' or '~1.5d'
+	quickProjectRx  = regexp.MustCompile(`^[+#](\S+)$`)                                // Match project: '+Family' or '#family'
+	quickKeywords   = map[string]bool{"at": true, "by": true, "due": true, "on": true} // Words before dates and times, dropped with them
+	quickDateWords  = map[string]bool{"by": true, "due": true, "on": true}             // Words before weekdays as dates
+)
+
+// QuickAddToken represents tokens of quick-add text interpreted as a field of the task.
+type QuickAddToken struct {
+	Token  string          // Interpreted text, e.g. "tomorrow", "by fri" or "#family".
+	Column int             // Byte column of the text in quick-add text, starting from 1.
+	Field  TaskSegmentType // Field set by the text, e.g. SegmentDueDate or SegmentProject.
+	Key    string          // Key of the additional tag for SegmentTag, e.g. "est".
+	Value  string          // Normalized value of the field, e.g. "2024-05-03" or "family".
+}
+
+// String returns the field and value for confirmation, e.g. "due: 2024-05-03" or "project: family".
+func (t QuickAddToken) String() string {
+	var name string
+	switch t.Field {
+	case SegmentPriority:
+		name = "priority"
+	case SegmentContext:
+		name = "context"
+	case SegmentProject:
+		name = "project"
+	case SegmentDueDate:
+		name = "due"
+	case SegmentThresholdDate:
+		name = "t"
+	case SegmentTag:
+		name = t.Key
+	default:
+		name = fieldName(t.Field)
+	}
+	return name + ": " + t.Value
+}
+
+// QuickAdd represents the result of ParseQuickAdd(): the new task and the tokens interpreted for it.
+type QuickAdd struct {
+	Task   *Task           // New task.
+	Tokens []QuickAddToken // Interpreted tokens in the order of quick-add text. The other words are the todo text.
+}
+
+// String returns the interpreted fields for confirmation, e.g. "due: 2024-05-03, project: family".
+func (q *QuickAdd) String() string {
+	strs := make([]string, len(q.Tokens))
+	for i, t := range q.Tokens {
+		strs[i] = t.String()
+	}
+	return strings.Join(strs, ", ")
+}
+
+// ParseQuickAdd parses free-form text typed by users into a new task with the given Options, e.g.
+//
+//	call mom tomorrow 5pm !A #family @phone ~30m
+//
+// Besides contexts, projects and tags in todo.txt format, it recognizes these tokens:
+//
+//	!A, !a, (A)                          priority
+//	#project                             project, same as +project
+//	tomorrow, eow, 2024-05-03            due date, bare relative dates or dates in DateLayout, see ParseRelativeDate()
+//	by friday, on mon+1w, due sun        due date with weekday, only right after "by", "on" or "due"
+//	5pm, 5:30pm, 17:00                   time of day, set as "time:17:00" tag
+//	~30m, ~2h, ~1.5d                     estimate, set as "est:2h" tag, same as est:2h
+//
+// Relative dates are always resolved, also in "due:" and "t:" tags, relative to the current date of the Clock.
+// The words "at", "by", "due" and "on" right before dates and times are dropped with them. Only the first priority,
+// due date and time of day are recognized, the others are kept in the todo text with all words not recognized,
+// e.g. the second time of day in "meeting 10:30am with bob 12:30" or the weekday in "watch monday night football".
+// The created date of the task is set to the current time of the Clock.
+//
+// Returns a *ParseError if a date of "due:" or "t:" tag is invalid.
+func ParseQuickAdd(text string, opts Options) (*QuickAdd, error) {
+	return NewParser(opts).QuickAdd(text)
+}
+
+// QuickAdd parses free-form text typed by users into a new task with options of the parser.
+// See ParseQuickAdd() for further information.
+func (p *Parser) QuickAdd(text string) (*QuickAdd, error) {
+	opts := p.opts
+	opts.RelativeDates = true
+	p = &Parser{opts: opts}
+
+	task := p.NewTask()
+	task.AdditionalTags = make(map[string]string)
+	result := &QuickAdd{Task: &task}
+
+	tokens := splitTokens(text)
+	positions := make([]int, len(tokens))
+	for i, pos := 0, 0; i < len(tokens); i++ {
+		positions[i] = pos + len(tokens[i].space)
+		pos = positions[i] + len(tokens[i].word)
+	}
+
+	var (
+		todo     []string
+		todoPos  []int // Index of token of each todo word
+		contexts = make(map[string]bool)
+		projects = make(map[string]bool)
+	)
+	// function for getting the todo word right before the token with given index, or empty string if there's none
+	wordBefore := func(i int) string {
+		if n := len(todo); n > 0 && todoPos[n-1] == i-1 {
+			return strings.ToLower(todo[n-1])
+		}
+		return emptyStr
+	}
+
+	// function for adding the interpreted token, dropping the keyword right before dates and times
+	interpret := func(i int, field TaskSegmentType, key, value string) {
+		token := QuickAddToken{Token: tokens[i].word, Column: positions[i] + 1, Field: field, Key: key, Value: value}
+		if field == SegmentDueDate || (field == SegmentTag && key == TimeTag) {
+			if n := len(todo); quickKeywords[wordBefore(i)] {
+				token.Token = todo[n-1] + tokens[i].space + token.Token
+				token.Column = positions[i-1] + 1
+				todo, todoPos = todo[:n-1], todoPos[:n-1]
+			}
+		}
+		result.Tokens = append(result.Tokens, token)
+	}
+
+	for i, t := range tokens {
+		word, lower := t.word, strings.ToLower(t.word)
+		switch {
+		case !task.HasPriority() && quickPriorityRx.MatchString(word):
+			match := quickPriorityRx.FindStringSubmatch(word)
+			task.Priority = strings.ToUpper(match[1] + match[2])
+			interpret(i, SegmentPriority, emptyStr, task.Priority)
+		case len(word) > 1 && word[0] == '@':
+			if name := word[1:]; !contexts[name] {
+				contexts[name] = true
+				task.Contexts = append(task.Contexts, name)
+			}
+			interpret(i, SegmentContext, emptyStr, word[1:])
+		case quickProjectRx.MatchString(word):
+			name := quickProjectRx.FindStringSubmatch(word)[1]
+			if !projects[name] {
+				projects[name] = true
+				task.Projects = append(task.Projects, name)
+			}
+			interpret(i, SegmentProject, emptyStr, name)
+		case !task.HasDueDate() && p.isQuickDate(word, quickDateWords[wordBefore(i)]):
+			task.DueDate, _ = p.parseDate(word)
+			interpret(i, SegmentDueDate, emptyStr, p.formatTime(task.DueDate))
+		case isEmpty(task.AdditionalTags[TimeTag]) && isQuickTime(lower):
+			task.AdditionalTags[TimeTag] = quickTime(lower)
+			interpret(i, SegmentTag, TimeTag, task.AdditionalTags[TimeTag])
+		case quickEstimateRx.MatchString(lower):
+			task.AdditionalTags[EstimateTag] = quickEstimateRx.FindStringSubmatch(lower)[1]
+			interpret(i, SegmentTag, EstimateTag, task.AdditionalTags[EstimateTag])
+		case !isQuickTime(lower) && addonTagTokenRx.MatchString(word):
+			match := addonTagTokenRx.FindStringSubmatch(word)
+			key, value := match[1], match[2]
+			switch key {
+			case "due", "t":
+				field := SegmentDueDate
+				if key == "t" {
+					field = SegmentThresholdDate
+				}
+				date, err := p.parseDate(value)
+				if err != nil {
+					return nil, &ParseError{
+						Column: positions[i] + len(key) + 2,
+						Text:   text,
+						Token:  value,
+						Field:  field,
+						Err:    err,
+					}
+				}
+				if key == "t" {
+					task.ThresholdDate = date
+				} else {
+					task.DueDate = date
+				}
+				interpret(i, field, emptyStr, p.formatTime(date))
+			default:
+				task.AdditionalTags[key] = value
+				interpret(i, SegmentTag, key, value)
+			}
+		default:
+			todo = append(todo, word)
+			todoPos = append(todoPos, i)
+		}
+	}
+
+	task.Todo = strings.Join(todo, " ")
+	task.Contexts, task.Projects = sortedStrings(task.Contexts), sortedStrings(task.Projects)
+	if len(task.AdditionalTags) == 0 {
+		task.AdditionalTags = nil
+	}
+	applyTimeTag(&task)
+	return result, nil
+}
+
+// isQuickDate returns true if the word is a bare due date of quick-add, i.e. a date in the date layout or a named relative date.
+// Abbreviations "tod" and "tom" are not accepted, as they are common words in todo text. Weekdays like "sun" or "monday"
+// are only accepted after a keyword like "by", as given by afterKeyword.
+func (p *Parser) isQuickDate(word string, afterKeyword bool) bool {
+	if _, err := p.parseTime(word); err == nil {
+		return true
+	}
+	match := relativeDateRx.FindStringSubmatch(strings.ToLower(word))
+	if match == nil || isEmpty(match[1]) || match[1] == "tod" || match[1] == "tom" {
+		return false
+	}
+	if isWeekday(match[1]) && !afterKeyword {
+		return false
+	}
+	_, err := relativeDate(word)
+	return err == nil
+}
+
+// isWeekday returns true if the lowercase name is a weekday or its abbreviation, e.g. "friday" or "fri".
+func isWeekday(name string) bool {
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		if full := strings.ToLower(wd.String()); name == full || name == full[:3] {
+			return true
+		}
+	}
+	return false
+}
+
+// isQuickTime returns true if the lowercase word is a time of day of quick-add, with minutes or am/pm.
+func isQuickTime(word string) bool {
+	match := quickTimeRx.FindStringSubmatch(word)
+	if match == nil || (isEmpty(match[2]) && isEmpty(match[3])) {
+		return false
+	}
+	hour, _ := strconv.Atoi(match[1])
+	minute, _ := strconv.Atoi(match[2])
+	if isNotEmpty(match[3]) {
+		return hour >= 1 && hour <= 12 && minute < 60
+	}
+	return hour < 24 && minute < 60
+}
+
+// quickTime returns the time of day of quick-add in 24-hour clock, e.g. "17:00" for "5pm".
+func quickTime(word string) string {
+	match := quickTimeRx.FindStringSubmatch(word)
+	hour, _ := strconv.Atoi(match[1])
+	minute, _ := strconv.Atoi(match[2])
+	switch match[3] {
+	case "am":
+		hour %= 12
+	case "pm":
+		hour = hour%12 + 12
+	}
+	return fmt.Sprintf("%02d:%02d", hour, minute)
+}
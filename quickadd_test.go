@@ -0,0 +1,118 @@
+package todotxt
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestParseQuickAdd(t *testing.T) {
+	opts := Options{Clock: FixedClock(time.Date(2024, 5, 2, 10, 0, 0, 0, time.Local))} // Thursday
+	tests := []struct {
+		text   string
+		task   string
+		report string
+	}{
+		{
+			"call mom tomorrow 5pm !A #family @phone",
+			"(A) 2024-05-02 call mom @phone +family time:17:00 due:2024-05-03",
+			"due: 2024-05-03, time: 17:00, priority: A, project: family, context: phone",
+		},
+		{
+			"Talk to Tom at 9:30am on mon ~1.5H",
+			"2024-05-02 Talk to Tom est:1.5h time:09:30 due:2024-05-06",
+			"time: 09:30, due: 2024-05-06, est: 1.5h",
+		},
+		{
+			"(B) review +work t:tom due:eow (A) 12am",
+			"(B) 2024-05-02 review (A) +work time:00:00 t:2024-05-03 due:2024-05-05",
+			"priority: B, project: work, t: 2024-05-03, due: 2024-05-05, time: 00:00",
+		},
+		{
+			"!c pay rent by 2024-05-10 fri 13:00 owner:bob #home +home",
+			"(C) 2024-05-02 pay rent fri +home owner:bob time:13:00 due:2024-05-10",
+			"priority: C, due: 2024-05-10, time: 13:00, owner: bob, project: home, project: home",
+		},
+		{
+			"buy sun cream by friday",
+			"2024-05-02 buy sun cream due:2024-05-03",
+			"due: 2024-05-03",
+		},
+		{
+			"wed dinner due sat",
+			"2024-05-02 wed dinner due:2024-05-04",
+			"due: 2024-05-04",
+		},
+		{
+			"meeting 10:30am with bob 12:30",
+			"2024-05-02 meeting with bob 12:30 time:10:30",
+			"time: 10:30",
+		},
+		{
+			"watch monday night football tomorrow",
+			"2024-05-02 watch monday night football due:2024-05-03",
+			"due: 2024-05-03",
+		},
+		{
+			"call tom on 13pm or 7",
+			"2024-05-02 call tom on 13pm or 7",
+			"",
+		},
+	}
+	for _, tt := range tests {
+		result, err := ParseQuickAdd(tt.text, opts)
+		if err != nil {
+			t.Errorf("Expected [%s] to be parsed, but got error: %v", tt.text, err)
+			continue
+		}
+		testExpected = tt.task
+		testGot = result.Task.String()
+		if testGot != testExpected {
+			t.Errorf("Expected Task of [%s] to be [%s], but got [%s]", tt.text, testExpected, testGot)
+		}
+		testExpected = tt.report
+		testGot = result.String()
+		if testGot != testExpected {
+			t.Errorf("Expected report of [%s] to be [%s], but got [%s]", tt.text, testExpected, testGot)
+		}
+	}
+
+	result, err := ParseQuickAdd("Pay rent  by fri", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Tokens) != 1 {
+		t.Fatalf("Expected 1 token, but got %d", len(result.Tokens))
+	}
+	token := result.Tokens[0]
+	testExpected = "by fri 11 due: 2024-05-03"
+	testGot = fmt.Sprint(token.Token, " ", token.Column, " ", token)
+	if testGot != testExpected {
+		t.Errorf("Expected token to be [%s], but got [%s]", testExpected, testGot)
+	}
+
+	// the new task can be parsed back, including the time of day
+	result, err = ParseQuickAdd("call mom 5pm ~30m", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseTask(result.Task.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	testExpected = result.Task.String()
+	testGot = parsed.String()
+	if testGot != testExpected || parsed.AdditionalTags[TimeTag] != "17:00" {
+		t.Errorf("Expected parsed Task to be [%s] with time [17:00], but got [%s] with time [%s]", testExpected, testGot, parsed.AdditionalTags[TimeTag])
+	}
+
+	_, err = ParseQuickAdd("x due:someday", opts)
+	var perr *ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("Expected ParseError, but got %v", err)
+	}
+	if perr.Column != 7 || perr.Token != "someday" || perr.Field != SegmentDueDate {
+		t.Errorf("Expected error at column 7 for due date [someday], but got %+v", perr)
+	}
+}
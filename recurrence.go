@@ -0,0 +1,218 @@
+package todotxt
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IntervalUnit represents unit of an Interval.
+type IntervalUnit byte
+
+// Units of Interval, as used in "rec:" tags.
+const (
+	IntervalDay         IntervalUnit = 'd'
+	IntervalBusinessDay IntervalUnit = 'b'
+	IntervalWeek        IntervalUnit = 'w'
+	IntervalMonth       IntervalUnit = 'm'
+	IntervalYear        IntervalUnit = 'y'
+)
+
+var intervalRx = regexp.MustCompile(`^(\d+)([dbwmy])$`) // Match interval: '3d' or '1w' or '2m' ...
+
+// Interval represents a calendar interval like "3d" (3 days), "2b" (2 business days), "1w", "6m" or "1y".
+type Interval struct {
+	Amount int
+	Unit   IntervalUnit
+}
+
+// ParseInterval parses the input text string like "3d" or "1w" into an Interval. Units are case-insensitive.
+func ParseInterval(s string) (Interval, error) {
+	match := intervalRx.FindStringSubmatch(strings.ToLower(s))
+	if match == nil {
+		return Interval{}, fmt.Errorf("invalid interval %q", s)
+	}
+	amount, err := strconv.Atoi(match[1])
+	if err != nil {
+		return Interval{}, fmt.Errorf("invalid interval %q: %w", s, err)
+	}
+	return Interval{Amount: amount, Unit: IntervalUnit(match[2][0])}, nil
+}
+
+// String returns the interval in the format of ParseInterval().
+func (i Interval) String() string {
+	return fmt.Sprintf("%d%c", i.Amount, i.Unit)
+}
+
+// AddTo returns the time t shifted by the interval.
+//
+// Adding months or years keeps the day of month, or uses the last day of the month if the day doesn't exist, e.g. 2020-01-31 + 1m = 2020-02-29.
+// Adding business days skips Saturdays and Sundays. Negative amounts shift t backwards.
+func (i Interval) AddTo(t time.Time) time.Time {
+	switch i.Unit {
+	case IntervalDay:
+		return t.AddDate(0, 0, i.Amount)
+	case IntervalWeek:
+		return t.AddDate(0, 0, 7*i.Amount)
+	case IntervalMonth:
+		return addMonths(t, i.Amount)
+	case IntervalYear:
+		return addMonths(t, 12*i.Amount)
+	case IntervalBusinessDay:
+		step, amount := 1, i.Amount
+		if amount < 0 {
+			step, amount = -1, -amount
+		}
+		for n := 0; n < amount; {
+			t = t.AddDate(0, 0, step)
+			if wd := t.Weekday(); wd != time.Saturday && wd != time.Sunday {
+				n++
+			}
+		}
+		return t
+	}
+	return t
+}
+
+// addMonths adds months to t, and clamps the day to the last day of the target month.
+func addMonths(t time.Time, months int) time.Time {
+	year, month, day := t.Date()
+	first := time.Date(year, month+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+// Recurrence represents the recurrence of a task given by the "rec:" tag, e.g. "rec:1w" or "rec:+2d".
+type Recurrence struct {
+	Interval
+	// Strict recurrence ("+" prefix) is based on the previous due date, otherwise on the completion date.
+	Strict bool
+}
+
+// ParseRecurrence parses the value of a "rec:" tag like "1w" or "+3m" into a Recurrence.
+func ParseRecurrence(s string) (Recurrence, error) {
+	rec := Recurrence{Strict: strings.HasPrefix(s, "+")}
+	interval, err := ParseInterval(strings.TrimPrefix(s, "+"))
+	if err != nil || interval.Amount == 0 {
+		return rec, fmt.Errorf("invalid recurrence %q", s)
+	}
+	rec.Interval = interval
+	return rec, nil
+}
+
+// String returns the recurrence in the format of "rec:" tag value.
+func (r Recurrence) String() string {
+	if r.Strict {
+		return "+" + r.Interval.String()
+	}
+	return r.Interval.String()
+}
+
+// HasRecurrence returns true if the task has a "rec:" tag.
+func (task *Task) HasRecurrence() bool {
+	_, found := task.AdditionalTags["rec"]
+	return found
+}
+
+// Recurrence returns the parsed Recurrence of the "rec:" tag.
+// Returns an error if the task has no or an invalid "rec:" tag.
+func (task *Task) Recurrence() (Recurrence, error) {
+	value, found := task.AdditionalTags["rec"]
+	if !found {
+		return Recurrence{}, fmt.Errorf("task has no recurrence")
+	}
+	return ParseRecurrence(value)
+}
+
+// NextRecurrence returns the next instance of a recurring task, or nil if the task has no "rec:" tag.
+// Returns an error if the "rec:" tag is invalid.
+//
+// The due date and threshold date ("t:" tag) of the new task are shifted by the interval:
+// from the previous dates for strict recurrence, or from the completion date otherwise (keeping the distance between threshold and due date).
+// If the task has neither, the new task is due one interval after completion.
+// The new task is not completed, and has the completion date as its created date if the task had one.
+func (task *Task) NextRecurrence() (*Task, error) {
+	if !task.HasRecurrence() {
+		return nil, nil
+	}
+	rec, err := task.Recurrence()
+	if err != nil {
+		return nil, err
+	}
+
+	completed := task.CompletedDate
+	if !task.HasCompletedDate() {
+		completed = task.now()
+	}
+	completed = time.Date(completed.Year(), completed.Month(), completed.Day(), 0, 0, 0, 0, completed.Location())
+
+	next := *task
+	next.ID = 0
+	next.Completed = false
+	next.CompletedDate = time.Time{}
+	next.Contexts = append([]string(nil), task.Contexts...)
+	next.Projects = append([]string(nil), task.Projects...)
+	next.AdditionalTags = make(map[string]string, len(task.AdditionalTags))
+	for key, value := range task.AdditionalTags {
+		next.AdditionalTags[key] = value
+	}
+	if task.HasCreatedDate() {
+		next.CreatedDate = completed
+	}
+
+	threshold, hasThreshold := task.ThresholdDate, task.HasThresholdDate()
+
+	switch {
+	case rec.Strict && task.HasDueDate():
+		next.DueDate = rec.AddTo(task.DueDate)
+		if hasThreshold {
+			threshold = rec.AddTo(threshold)
+		}
+	case rec.Strict && hasThreshold:
+		threshold = rec.AddTo(threshold)
+	case task.HasDueDate():
+		next.DueDate = rec.AddTo(completed)
+		if hasThreshold {
+			threshold = next.DueDate.AddDate(0, 0, daysBetween(task.DueDate, threshold))
+		}
+	case hasThreshold:
+		threshold = rec.AddTo(completed)
+	default:
+		next.DueDate = rec.AddTo(completed)
+	}
+	next.ThresholdDate = threshold
+	if task.HasDueTime() && next.HasDueDate() {
+		next.DueDate = withTimeOfDay(next.DueDate, task.DueDate)
+	}
+	return &next, nil
+}
+
+// CompleteTask completes the Task with given task 'id' in the TaskList.
+// If the task is recurring, the next instance is added to the TaskList via AddTask() and returned, otherwise nil is returned.
+// Returns an error if Task could not be found, or its "rec:" tag is invalid.
+func (tasklist *TaskList) CompleteTask(id int) (*Task, error) {
+	task, err := tasklist.GetTask(id)
+	if err != nil {
+		return nil, err
+	}
+	if task.Completed {
+		return nil, nil
+	}
+	if task.HasRecurrence() {
+		if _, err := task.Recurrence(); err != nil {
+			return nil, err
+		}
+	}
+
+	task.Complete()
+	next, err := task.NextRecurrence()
+	if err != nil || next == nil {
+		return nil, err
+	}
+	tasklist.AddTask(next)
+	return &(*tasklist)[len(*tasklist)-1], nil
+}
@@ -0,0 +1,212 @@
+package todotxt
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseInterval(t *testing.T) {
+	cases := []struct {
+		text     string
+		interval Interval
+		valid    bool
+	}{
+		{"3d", Interval{3, IntervalDay}, true},
+		{"1W", Interval{1, IntervalWeek}, true},
+		{"12m", Interval{12, IntervalMonth}, true},
+		{"2y", Interval{2, IntervalYear}, true},
+		{"5b", Interval{5, IntervalBusinessDay}, true},
+		{"0d", Interval{0, IntervalDay}, true},
+		{"d", Interval{}, false},
+		{"3", Interval{}, false},
+		{"-3d", Interval{}, false},
+		{"3h", Interval{}, false},
+		{"99999999999999999999d", Interval{}, false},
+	}
+	for _, c := range cases {
+		interval, err := ParseInterval(c.text)
+		if (err == nil) != c.valid {
+			t.Errorf("Expected ParseInterval(%q) to be valid: %v, but got error: %v", c.text, c.valid, err)
+		}
+		if interval != c.interval {
+			t.Errorf("Expected ParseInterval(%q) to be %v, but got %v", c.text, c.interval, interval)
+		}
+	}
+}
+
+func TestIntervalAddTo(t *testing.T) {
+	cases := []struct {
+		date     string
+		interval string
+		expected string
+	}{
+		{"2020-02-27", "3d", "2020-03-01"},
+		{"2020-02-27", "2w", "2020-03-12"},
+		{"2020-01-31", "1m", "2020-02-29"},
+		{"2020-03-31", "1m", "2020-04-30"},
+		{"2020-12-15", "2m", "2021-02-15"},
+		{"2020-02-29", "1y", "2021-02-28"},
+		{"2020-05-01", "1b", "2020-05-04"},
+		{"2020-05-01", "5b", "2020-05-08"},
+		{"2020-05-02", "1b", "2020-05-04"},
+		{"2020-05-04", "0b", "2020-05-04"},
+	}
+	for _, c := range cases {
+		date, _ := parseTime(c.date)
+		interval, err := ParseInterval(c.interval)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := interval.AddTo(date).Format(DateLayout); got != c.expected {
+			t.Errorf("Expected %s + %s to be %s, but got %s", c.date, c.interval, c.expected, got)
+		}
+	}
+
+	// negative amounts
+	date, _ := parseTime("2020-05-04")
+	testExpected = "2020-04-30"
+	testGot = Interval{-2, IntervalBusinessDay}.AddTo(date).Format(DateLayout)
+	if testGot != testExpected {
+		t.Errorf("Expected 2020-05-04 - 2b to be %s, but got %s", testExpected, testGot)
+	}
+	testExpected = "2020-04-27"
+	testGot = Interval{-1, IntervalWeek}.AddTo(date).Format(DateLayout)
+	if testGot != testExpected {
+		t.Errorf("Expected 2020-05-04 - 1w to be %s, but got %s", testExpected, testGot)
+	}
+}
+
+func TestParseRecurrence(t *testing.T) {
+	cases := []struct {
+		text  string
+		rec   Recurrence
+		valid bool
+	}{
+		{"1w", Recurrence{Interval{1, IntervalWeek}, false}, true},
+		{"+2d", Recurrence{Interval{2, IntervalDay}, true}, true},
+		{"+3b", Recurrence{Interval{3, IntervalBusinessDay}, true}, true},
+		{"0d", Recurrence{}, false},
+		{"+", Recurrence{Strict: true}, false},
+		{"weekly", Recurrence{}, false},
+	}
+	for _, c := range cases {
+		rec, err := ParseRecurrence(c.text)
+		if (err == nil) != c.valid {
+			t.Errorf("Expected ParseRecurrence(%q) to be valid: %v, but got error: %v", c.text, c.valid, err)
+		}
+		if rec != c.rec {
+			t.Errorf("Expected ParseRecurrence(%q) to be %v, but got %v", c.text, c.rec, rec)
+		}
+		if c.valid && rec.String() != c.text {
+			t.Errorf("Expected Recurrence to be %q, but got %q", c.text, rec.String())
+		}
+	}
+}
+
+func TestTaskNextRecurrence(t *testing.T) {
+	clock := FixedClock(time.Date(2020, 5, 6, 10, 30, 0, 0, time.Local)) // Wednesday
+	cases := []struct {
+		text     string
+		expected string
+	}{
+		{"Water plants rec:1w", "Water plants rec:1w due:2020-05-13"},
+		{"Water plants rec:+1w", "Water plants rec:+1w due:2020-05-13"},
+		{"2020-04-01 Pay rent rec:+1m due:2020-05-01", "2020-05-06 Pay rent rec:+1m due:2020-06-01"},
+		{"Pay rent rec:1m due:2020-05-01", "Pay rent rec:1m due:2020-06-06"},
+		{"(A) Report @Work rec:+1b due:2020-05-08 t:2020-05-07", "(A) Report @Work rec:+1b t:2020-05-08 due:2020-05-11"},
+		{"(A) Report @Work rec:2b due:2020-05-08 t:2020-05-07", "(A) Report @Work rec:2b t:2020-05-07 due:2020-05-08"},
+		{"Backup rec:3d t:2020-05-01", "Backup rec:3d t:2020-05-09"},
+		{"Backup rec:+3d t:2020-05-01", "Backup rec:+3d t:2020-05-04"},
+		{"x 2020-05-01 Backup rec:1d", "Backup rec:1d due:2020-05-02"},
+	}
+	for _, c := range cases {
+		task, err := ParseTask(c.text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		task.SetClock(clock)
+		task.Complete()
+
+		next, err := task.NextRecurrence()
+		if err != nil {
+			t.Errorf("Expected NextRecurrence of [%s] to succeed, but got: %v", c.text, err)
+			continue
+		}
+		if got := next.String(); got != c.expected {
+			t.Errorf("Expected NextRecurrence of [%s] to be [%s], but got [%s]", c.text, c.expected, got)
+		}
+		if !task.Completed || next.Completed {
+			t.Errorf("Expected only the original task [%s] to be completed", c.text)
+		}
+	}
+
+	task, _ := ParseTask("Water plants")
+	if next, err := task.NextRecurrence(); next != nil || err != nil {
+		t.Errorf("Expected no next recurrence for non-recurring task, but got [%v], error: %v", next, err)
+	}
+	task, _ = ParseTask("Water plants rec:often")
+	if next, err := task.NextRecurrence(); next != nil || err == nil {
+		t.Errorf("Expected error for invalid recurrence, but got [%v]", next)
+	}
+	if _, err := task.Recurrence(); err == nil {
+		t.Errorf("Expected error for invalid recurrence")
+	}
+	task, _ = ParseTask("Water plants")
+	if _, err := task.Recurrence(); err == nil {
+		t.Errorf("Expected error for missing recurrence")
+	}
+}
+
+func TestTaskListCompleteTask(t *testing.T) {
+	if err := testTasklist.LoadFromPath(testInputTasklist); err != nil {
+		t.Fatal(err)
+	}
+	task := Task{Todo: "Water plants", AdditionalTags: map[string]string{"rec": "+1w"}}
+	testTasklist.AddTask(&task)
+	task.ID = 0
+	task.AdditionalTags = map[string]string{"rec": "often"}
+	testTasklist.AddTask(&task)
+	testTasklist.SetClock(FixedClock(time.Date(2020, 5, 6, 10, 30, 0, 0, time.Local)))
+
+	next, err := testTasklist.CompleteTask(64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testExpected = "Water plants rec:+1w due:2020-05-13"
+	testGot = next.String()
+	if testGot != testExpected {
+		t.Errorf("Expected next Task to be [%s], but got [%s]", testExpected, testGot)
+	}
+	testExpected = 66
+	testGot = next.ID
+	if testGot != testExpected {
+		t.Errorf("Expected next Task to have ID [%d], but got [%d]", testExpected, testGot)
+	}
+	if completed, _ := testTasklist.GetTask(64); !completed.Completed {
+		t.Errorf("Expected Task[64] to be completed")
+	}
+
+	// completing again doesn't create another instance
+	if next, err := testTasklist.CompleteTask(64); next != nil || err != nil {
+		t.Errorf("Expected no next Task for completed task, but got [%v], error: %v", next, err)
+	}
+	// non-recurring task
+	if next, err := testTasklist.CompleteTask(1); next != nil || err != nil {
+		t.Errorf("Expected no next Task for non-recurring task, but got [%v], error: %v", next, err)
+	}
+	// invalid recurrence
+	if _, err := testTasklist.CompleteTask(65); err == nil {
+		t.Errorf("Expected CompleteTask to fail for invalid recurrence")
+	} else if invalid, _ := testTasklist.GetTask(65); invalid.Completed {
+		t.Errorf("Expected Task with invalid recurrence not to be completed")
+	}
+	if _, err := testTasklist.CompleteTask(99); err == nil {
+		t.Errorf("Expected CompleteTask to fail for missing task")
+	}
+
+	testExpected = 66
+	testGot = len(testTasklist)
+	if testGot != testExpected {
+		t.Errorf("Expected TaskList to contain %d tasks, but got %d", testExpected, testGot)
+	}
+}
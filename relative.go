@@ -0,0 +1,135 @@
+package todotxt
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	relativeDateRx = regexp.MustCompile(`^([a-z]+)?(?:([+-])(\d+[dbwmy]))?$`) // Match relative date: 'tomorrow', 'fri+1w' or '+3d'
+	// Match relative created date followed by todo text: '(A) today ...' or 'x 2012-12-12 yesterday ...' or 'today ...'
+	createdRelativeRx = regexp.MustCompile(`^(\([A-Z]\)|x \d{4}-\d{2}-\d{2}(?:T\d{2}:\d{2})? \([A-Z]\)|x \([A-Z]\)|x \d{4}-\d{2}-\d{2}(?:T\d{2}:\d{2})?|)\s*(today|yesterday)\s+`)
+
+	// Days from the reference date for the named relative dates.
+	relativeDays = map[string]func(ref time.Time) int{
+		"today":     func(time.Time) int { return 0 },
+		"tod":       func(time.Time) int { return 0 },
+		"tomorrow":  func(time.Time) int { return 1 },
+		"tom":       func(time.Time) int { return 1 },
+		"yesterday": func(time.Time) int { return -1 },
+		"eow":       func(ref time.Time) int { return (7 - int(ref.Weekday())) % 7 },
+		"eom": func(ref time.Time) int {
+			return time.Date(ref.Year(), ref.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day() - ref.Day()
+		},
+		"eoy": func(ref time.Time) int {
+			return time.Date(ref.Year(), time.December, 31, 0, 0, 0, 0, time.UTC).YearDay() - ref.YearDay()
+		},
+	}
+)
+
+func init() {
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		wd := wd
+		nextWeekday := func(ref time.Time) int {
+			return (int(wd)-int(ref.Weekday())+6)%7 + 1
+		}
+		name := strings.ToLower(wd.String())
+		relativeDays[name] = nextWeekday
+		relativeDays[name[:3]] = nextWeekday
+	}
+}
+
+// ParseRelativeDate parses the relative or natural date expression into the absolute date for the reference time.
+// The result is the midnight of the date in the location of the reference time. Expressions are case-insensitive:
+//
+//	today, tod, tomorrow, tom, yesterday    the reference date, the day after and the day before
+//	monday, mon, ..., sunday, sun           the next weekday after the reference date, i.e. 1 to 7 days later
+//	eow, eom, eoy                           end of week (Sunday), month and year, it's the reference date on the last day
+//	+3d, -1w, +2b, +1m, +1y                 the reference date shifted by the interval, see ParseInterval() for units
+//	fri+1w, eom-2b, today+3d                the named date shifted by the interval
+//
+// Returns an error if the expression is not a relative date.
+func ParseRelativeDate(s string, ref time.Time) (time.Time, error) {
+	resolve, err := relativeDate(s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return resolve(ref), nil
+}
+
+// relativeDate parses the relative date expression, and returns the function to resolve the date for the reference time.
+func relativeDate(s string) (func(ref time.Time) time.Time, error) {
+	match := relativeDateRx.FindStringSubmatch(strings.ToLower(s))
+	if match == nil || isEmpty(match[0]) {
+		return nil, fmt.Errorf("invalid date %q", s)
+	}
+
+	days := relativeDays["today"]
+	if isNotEmpty(match[1]) {
+		var found bool
+		if days, found = relativeDays[match[1]]; !found {
+			return nil, fmt.Errorf("invalid date %q", s)
+		}
+	}
+	var interval Interval
+	if isNotEmpty(match[3]) {
+		var err error
+		if interval, err = ParseInterval(match[3]); err != nil {
+			return nil, err
+		}
+		if match[2] == "-" {
+			interval.Amount = -interval.Amount
+		}
+	}
+	return func(ref time.Time) time.Time {
+		year, month, day := ref.Date()
+		date := time.Date(year, month, day+days(ref), 0, 0, 0, 0, ref.Location())
+		return interval.AddTo(date)
+	}, nil
+}
+
+// isRelativeDate returns true if the string is a relative date expression accepted by the parser, rather than a date in its layout.
+func (p *Parser) isRelativeDate(s string) bool {
+	if !p.opts.RelativeDates {
+		return false
+	}
+	if _, err := p.parseTime(s); err == nil {
+		return false
+	}
+	_, err := relativeDate(s)
+	return err == nil
+}
+
+// isCreatedRelativeDate returns true if the word is a relative date accepted by the parser as created date.
+// Only "today" and "yesterday" are accepted, so words like "Friday" or "tomorrow" at the beginning of todo text are kept.
+func (p *Parser) isCreatedRelativeDate(s string) bool {
+	return p.opts.RelativeDates && (s == "today" || s == "yesterday")
+}
+
+// parseDate parses the date string with the date layout of the parser, or as relative date for the Clock of the parser if RelativeDates is enabled.
+func (p *Parser) parseDate(s string) (time.Time, error) {
+	date, err := p.parseTime(s)
+	if err != nil && p.opts.RelativeDates {
+		if resolve, rerr := relativeDate(s); rerr == nil {
+			return resolve(p.now()), nil
+		}
+	}
+	return date, err
+}
+
+// hasRelativeDates returns true if the created date, due date or threshold date of the task text is a relative date resolved by the parser.
+func (p *Parser) hasRelativeDates(text string) bool {
+	tokens := splitTokens(text)
+	n := p.prefixTokenCount(tokens)
+	if n > 0 && p.isCreatedRelativeDate(tokens[n-1].word) {
+		return true
+	}
+	for _, t := range tokens[n:] {
+		if match := addonTagTokenRx.FindStringSubmatch(t.word); match != nil && (match[1] == "due" || match[1] == "t") && p.isRelativeDate(match[2]) {
+			return true
+		}
+	}
+	return false
+}
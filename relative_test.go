@@ -0,0 +1,191 @@
+package todotxt
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRelativeDate(t *testing.T) {
+	ref := time.Date(2020, 2, 12, 15, 4, 5, 0, time.Local) // Wednesday
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"today", "2020-02-12"},
+		{"tod", "2020-02-12"},
+		{"Tomorrow", "2020-02-13"},
+		{"tom", "2020-02-13"},
+		{"yesterday", "2020-02-11"},
+		{"fri", "2020-02-14"},
+		{"wed", "2020-02-19"},
+		{"Monday", "2020-02-17"},
+		{"SUN", "2020-02-16"},
+		{"eow", "2020-02-16"},
+		{"eom", "2020-02-29"},
+		{"eoy", "2020-12-31"},
+		{"+3d", "2020-02-15"},
+		{"-1w", "2020-02-05"},
+		{"+2b", "2020-02-14"},
+		{"+1m", "2020-03-12"},
+		{"today+1y", "2021-02-12"},
+		{"fri+1w", "2020-02-21"},
+		{"eom-2b", "2020-02-27"},
+	}
+	for _, tt := range tests {
+		date, err := ParseRelativeDate(tt.expr, ref)
+		if err != nil {
+			t.Errorf("Expected [%s] to be parsed, but got error: %v", tt.expr, err)
+			continue
+		}
+		testExpected = tt.want
+		testGot = date.Format(DateLayout)
+		if testGot != testExpected {
+			t.Errorf("Expected [%s] to be [%s], but got [%s]", tt.expr, testExpected, testGot)
+		}
+		if h, m, s := date.Clock(); h != 0 || m != 0 || s != 0 {
+			t.Errorf("Expected [%s] to be midnight, but got [%s]", tt.expr, date)
+		}
+	}
+
+	sunday := time.Date(2020, 2, 16, 0, 0, 0, 0, time.Local)
+	testExpected = "2020-02-16"
+	if date, err := ParseRelativeDate("eow", sunday); err != nil {
+		t.Errorf("Expected eow to be parsed, but got error: %v", err)
+	} else if testGot = date.Format(DateLayout); testGot != testExpected {
+		t.Errorf("Expected eow on Sunday to be [%s], but got [%s]", testExpected, testGot)
+	}
+
+	for _, expr := range []string{"", "someday", "3d", "+3x", "fri+", "2020-02-30", "today tomorrow"} {
+		if _, err := ParseRelativeDate(expr, ref); err == nil {
+			t.Errorf("Expected [%s] to be invalid, but got no error", expr)
+		}
+	}
+}
+
+func TestParserRelativeDates(t *testing.T) {
+	clock := FixedClock(time.Date(2020, 2, 12, 15, 4, 5, 0, time.Local))
+	parser := NewParser(Options{RelativeDates: true, Clock: clock})
+
+	task, err := parser.Parse("(A) 2020-02-10 Call Mom @Phone due:fri t:+1d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testExpected = "(A) 2020-02-10 Call Mom @Phone t:2020-02-13 due:2020-02-14"
+	testGot = task.String()
+	if testGot != testExpected {
+		t.Errorf("Expected Task to be [%s], but got [%s]", testExpected, testGot)
+	}
+	testExpected = "Call Mom"
+	testGot = task.Todo
+	if testGot != testExpected {
+		t.Errorf("Expected Task to have todo [%s], but got [%s]", testExpected, testGot)
+	}
+
+	task, err = parser.Parse("x 2020-02-12 Done due:eom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testExpected = "x 2020-02-12 Done due:2020-02-29"
+	testGot = task.String()
+	if testGot != testExpected {
+		t.Errorf("Expected Task to be [%s], but got [%s]", testExpected, testGot)
+	}
+
+	// only "today" and "yesterday" followed by todo text are relative created dates
+	for _, tc := range []struct {
+		text     string
+		expected string
+	}{
+		{"today Call Mom", "2020-02-12 Call Mom"},
+		{"(A) yesterday Call Mom", "(A) 2020-02-11 Call Mom"},
+		{"x 2020-02-12 today Call Mom", "x 2020-02-12 2020-02-12 Call Mom"},
+	} {
+		task, err = parser.Parse(tc.text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testExpected = tc.expected
+		testGot = task.String()
+		if testGot != testExpected {
+			t.Errorf("Expected Task to be [%s], but got [%s]", testExpected, testGot)
+		}
+	}
+	for _, text := range []string{"Friday night pizza", "tomorrow Call Mom", "Today Call Mom", "(A) today", "today"} {
+		task, err = parser.Parse(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if task.HasCreatedDate() || !strings.HasSuffix(text, task.Todo) {
+			t.Errorf("Expected Task [%s] to have no created date, but got [%s] with todo [%s]", text, task.CreatedDate, task.Todo)
+		}
+	}
+	if task, err = NewParser(Options{Clock: clock}).Parse("today Call Mom"); err != nil || task.HasCreatedDate() {
+		t.Errorf("Expected Task to have no created date without RelativeDates, but got [%s], error: %v", task.CreatedDate, err)
+	}
+
+	if _, err := parser.Parse("x tomorrow Done"); err != nil {
+		t.Errorf("Expected completed date not to be relative, but got error: %v", err)
+	}
+	if _, err := parser.Parse("Call Mom due:someday"); err == nil {
+		t.Errorf("Expected Parse to fail for invalid date, but it didn't")
+	}
+	if _, err := NewParser(Options{Clock: clock}).Parse("Call Mom due:tomorrow"); err == nil {
+		t.Errorf("Expected Parse to fail for relative date without RelativeDates, but it didn't")
+	}
+}
+
+func TestParserRewriteRelativeDates(t *testing.T) {
+	clock := FixedClock(time.Date(2020, 2, 12, 15, 4, 5, 0, time.Local))
+	text := "2020-02-10  Call Mom due:tomorrow   @Phone"
+
+	keep := NewParser(Options{RelativeDates: true, PreserveFormat: true, Clock: clock})
+	task, err := keep.Parse(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testExpected = text
+	testGot = keep.Format(task)
+	if testGot != testExpected {
+		t.Errorf("Expected Task to be [%s], but got [%s]", testExpected, testGot)
+	}
+
+	rewrite := NewParser(Options{RelativeDates: true, RewriteRelativeDates: true, PreserveFormat: true, Clock: clock})
+	testExpected = "2020-02-10  Call Mom due:2020-02-13   @Phone"
+	testGot = rewrite.Format(task)
+	if testGot != testExpected {
+		t.Errorf("Expected Task to be [%s], but got [%s]", testExpected, testGot)
+	}
+
+	task.Priority = "B"
+	testExpected = "(B) 2020-02-10  Call Mom due:2020-02-13   @Phone"
+	testGot = rewrite.Format(task)
+	if testGot != testExpected {
+		t.Errorf("Expected Task to be [%s], but got [%s]", testExpected, testGot)
+	}
+
+	// the dates resolved at parse time are kept if the tasks are written on another day
+	later := NewParser(Options{RelativeDates: true, PreserveFormat: true, Clock: FixedClock(time.Date(2020, 2, 20, 0, 0, 0, 0, time.Local))})
+	task.Priority = emptyStr
+	testExpected = "2020-02-10  Call Mom due:2020-02-13   @Phone"
+	testGot = later.Format(task)
+	if testGot != testExpected {
+		t.Errorf("Expected Task to be [%s], but got [%s]", testExpected, testGot)
+	}
+
+	// relative created dates are kept or rewritten as well
+	task, err = keep.Parse("yesterday  Call Mom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testExpected = "yesterday  Call Mom"
+	testGot = keep.Format(task)
+	if testGot != testExpected {
+		t.Errorf("Expected Task to be [%s], but got [%s]", testExpected, testGot)
+	}
+	testExpected = "2020-02-11  Call Mom"
+	testGot = rewrite.Format(task)
+	if testGot != testExpected {
+		t.Errorf("Expected Task to be [%s], but got [%s]", testExpected, testGot)
+	}
+}
@@ -20,6 +20,7 @@ const (
 	SegmentProject
 	SegmentTag
 	SegmentDueDate
+	SegmentThresholdDate
 )
 
 // TaskSegment represents a segment in task string.
@@ -29,8 +30,16 @@ type TaskSegment struct {
 	Display   string
 }
 
-// Segments returns a segmented task string in todo.txt format. The order of segments is the same as String().
+// Segments returns a segmented task string in todo.txt format. The order of segments is the same as String() without PreserveFormat.
+//
+// The package-level variables are used as options, see Parser.Segments() for other options.
 func (task *Task) Segments() []*TaskSegment {
+	return defaultParser().Segments(task)
+}
+
+// Segments returns a segmented task string in todo.txt format with options of the parser.
+// See Task.Segments() for further information.
+func (p *Parser) Segments(task *Task) []*TaskSegment {
 	var segs []*TaskSegment
 	newBasicTaskSeg := func(t TaskSegmentType, s string) *TaskSegment {
 		return &TaskSegment{
@@ -50,16 +59,16 @@ func (task *Task) Segments() []*TaskSegment {
 	if task.Completed {
 		segs = append(segs, newBasicTaskSeg(SegmentIsCompleted, "x"))
 		if task.HasCompletedDate() {
-			segs = append(segs, newBasicTaskSeg(SegmentCompletedDate, task.CompletedDate.Format(DateLayout)))
+			segs = append(segs, newBasicTaskSeg(SegmentCompletedDate, p.formatCompletedDate(task)))
 		}
 	}
 
-	if task.HasPriority() && (!task.Completed || !RemoveCompletedPriority) {
+	if task.HasPriority() && (!task.Completed || !p.opts.RemoveCompletedPriority) {
 		segs = append(segs, newTaskSeg(SegmentPriority, task.Priority, fmt.Sprintf("(%s)", task.Priority)))
 	}
 
 	if task.HasCreatedDate() {
-		segs = append(segs, newBasicTaskSeg(SegmentCreatedDate, task.CreatedDate.Format(DateLayout)))
+		segs = append(segs, newBasicTaskSeg(SegmentCreatedDate, p.formatTime(task.CreatedDate)))
 	}
 
 	segs = append(segs, newBasicTaskSeg(SegmentTodoText, task.Todo))
@@ -95,8 +104,12 @@ func (task *Task) Segments() []*TaskSegment {
 		}
 	}
 
+	if task.HasThresholdDate() {
+		segs = append(segs, newBasicTaskSeg(SegmentThresholdDate, fmt.Sprintf("t:%s", p.formatTime(task.ThresholdDate))))
+	}
+
 	if task.HasDueDate() {
-		segs = append(segs, newBasicTaskSeg(SegmentDueDate, fmt.Sprintf("due:%s", task.DueDate.Format(DateLayout))))
+		segs = append(segs, newBasicTaskSeg(SegmentDueDate, fmt.Sprintf("due:%s", p.formatDueDate(task))))
 	}
 	return segs
 }
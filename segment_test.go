@@ -24,6 +24,7 @@ func TestTaskTaskSegmentType(t *testing.T) {
 		SegmentProject:       "Project",
 		SegmentTag:           "Tag",
 		SegmentDueDate:       "DueDate",
+		SegmentThresholdDate: "ThresholdDate",
 		0:                    "TaskSegmentType(0)",
 	}
 	for n, s := range names {
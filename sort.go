@@ -3,6 +3,8 @@ package todotxt
 import (
 	"errors"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -28,44 +30,181 @@ const (
 	SortContextDesc
 	SortProjectAsc
 	SortProjectDesc
+	SortThresholdDateAsc
+	SortThresholdDateDesc
+	SortUrgencyAsc
+	SortUrgencyDesc
 )
 
 // Sort allows a TaskList to be sorted by certain predefined fields. Multiple-key sorting is supported.
-// See constants Sort* for fields and sort order.
+// See constants Sort* for fields and sort order, and SortBy() for sorting by tags and custom comparators.
+//
+// Comment and blank lines keep their positions, and the tasks between each two of them are sorted on their own,
+// so tasks stay in the section after their comment line.
 func (tasklist *TaskList) Sort(flag TaskSortByType, flags ...TaskSortByType) error {
-	combined := make([]TaskSortByType, len(flags)+1)
-	j := 0
-	for i := len(flags) - 1; i >= 0; i-- {
-		combined[j] = flags[i]
-		j++
-	}
-	combined[j] = flag
-
-	for _, flag := range combined {
-		switch flag {
-		case SortTaskIDAsc, SortTaskIDDesc:
-			tasklist.sortByTaskID(flag)
-		case SortTodoTextAsc, SortTodoTextDesc:
-			tasklist.sortByTodoText(flag)
-		case SortPriorityAsc, SortPriorityDesc:
-			tasklist.sortByPriority(flag)
-		case SortCreatedDateAsc, SortCreatedDateDesc:
-			tasklist.sortByCreatedDate(flag)
-		case SortCompletedDateAsc, SortCompletedDateDesc:
-			tasklist.sortByCompletedDate(flag)
-		case SortDueDateAsc, SortDueDateDesc:
-			tasklist.sortByDueDate(flag)
-		case SortContextAsc, SortContextDesc:
-			tasklist.sortByContext(flag)
-		case SortProjectAsc, SortProjectDesc:
-			tasklist.sortByProject(flag)
-		default:
-			return errors.New("unrecognized sort option")
+	keys := make([]SortKey, len(flags))
+	for i, f := range flags {
+		keys[i] = f
+	}
+	return tasklist.SortBy(flag, keys...)
+}
+
+// SortKey represents a sorting element and order for TaskList.SortBy().
+// It's one of the TaskSortByType flags, or returned by SortFunc(), SortTagAsc() and SortTagDesc().
+type SortKey interface {
+	sortTaskList(tasklist *TaskList) error
+}
+
+// SortBy allows a TaskList to be sorted by predefined fields, additional tags and custom comparators. Multiple-key sorting is supported,
+// the TaskList is sorted stably by each key in reverse order, so the first key is the primary one, just like Sort().
+//
+// Comment and blank lines keep their positions, and the tasks between each two of them are sorted on their own.
+func (tasklist *TaskList) SortBy(key SortKey, keys ...SortKey) error {
+	for i := len(keys) - 1; i >= 0; i-- {
+		if err := keys[i].sortTaskList(tasklist); err != nil {
+			return err
 		}
 	}
+	return key.sortTaskList(tasklist)
+}
+
+// sortTaskList sorts the TaskList by the flag, so TaskSortByType implements SortKey.
+func (flag TaskSortByType) sortTaskList(tasklist *TaskList) error {
+	switch flag {
+	case SortTaskIDAsc, SortTaskIDDesc:
+		tasklist.sortByTaskID(flag)
+	case SortTodoTextAsc, SortTodoTextDesc:
+		tasklist.sortByTodoText(flag)
+	case SortPriorityAsc, SortPriorityDesc:
+		tasklist.sortByPriority(flag)
+	case SortCreatedDateAsc, SortCreatedDateDesc:
+		tasklist.sortByCreatedDate(flag)
+	case SortCompletedDateAsc, SortCompletedDateDesc:
+		tasklist.sortByCompletedDate(flag)
+	case SortDueDateAsc, SortDueDateDesc:
+		tasklist.sortByDueDate(flag)
+	case SortContextAsc, SortContextDesc:
+		tasklist.sortByContext(flag)
+	case SortProjectAsc, SortProjectDesc:
+		tasklist.sortByProject(flag)
+	case SortThresholdDateAsc, SortThresholdDateDesc:
+		tasklist.sortByThresholdDate(flag)
+	case SortUrgencyAsc, SortUrgencyDesc:
+		tasklist.sortByUrgency(DefaultUrgency, flag == SortUrgencyDesc)
+	default:
+		return errors.New("unrecognized sort option")
+	}
+	return nil
+}
+
+// sortFunc is a SortKey of a custom comparator.
+type sortFunc func(t1, t2 *Task) int
+
+// SortFunc returns a SortKey sorting tasks by the comparator, which returns a negative number if t1 sorts before t2,
+// a positive number if t1 sorts after t2, and zero if their order should be kept.
+func SortFunc(cmp func(t1, t2 *Task) int) SortKey {
+	return sortFunc(cmp)
+}
+
+func (cmp sortFunc) sortTaskList(tasklist *TaskList) error {
+	if cmp == nil {
+		return errors.New("nil sort function")
+	}
+	tasklist.sortBy(func(t1, t2 *Task) bool {
+		return cmp(t1, t2) < 0
+	})
 	return nil
 }
 
+// TagType represents how values of additional tags are compared for sorting.
+type TagType uint8
+
+// Types of values of additional tags.
+const (
+	TagString TagType = iota // Values are compared as strings, e.g. "owner:bob".
+	TagNumber                // Values are compared as numbers, e.g. "est:1.5".
+	TagDate                  // Values are compared as dates in DateLayout, e.g. "t:2020-01-01".
+)
+
+// sortTag is a SortKey of an additional tag.
+type sortTag struct {
+	key  string
+	typ  TagType
+	desc bool
+}
+
+// SortTagAsc returns a SortKey sorting tasks by the value of the additional tag in ascending order.
+// The known date tags "due" and "t" are sorted by the DueDate and ThresholdDate fields.
+// Tasks without the tag or with a value that can't be parsed as the given type are sorted last.
+func SortTagAsc(key string, typ TagType) SortKey {
+	return sortTag{key: key, typ: typ}
+}
+
+// SortTagDesc returns a SortKey sorting tasks by the value of the additional tag in descending order.
+// See SortTagAsc() for further information.
+func SortTagDesc(key string, typ TagType) SortKey {
+	return sortTag{key: key, typ: typ, desc: true}
+}
+
+func (st sortTag) sortTaskList(tasklist *TaskList) error {
+	if st.typ > TagDate {
+		return errors.New("unrecognized tag type")
+	}
+	tasklist.sortBy(st.less)
+	return nil
+}
+
+// less returns true if the tag value of t1 sorts before the one of t2.
+func (st sortTag) less(t1, t2 *Task) bool {
+	v1, ok1 := tagValue(t1, st.key)
+	v2, ok2 := tagValue(t2, st.key)
+
+	var c int
+	switch st.typ {
+	case TagNumber:
+		f1, err1 := strconv.ParseFloat(v1, 64)
+		f2, err2 := strconv.ParseFloat(v2, 64)
+		ok1, ok2 = ok1 && err1 == nil, ok2 && err2 == nil
+		if f1 < f2 {
+			c = -1
+		} else if f1 > f2 {
+			c = 1
+		}
+	case TagDate:
+		d1, err1 := parseTime(v1)
+		d2, err2 := parseTime(v2)
+		ok1, ok2 = ok1 && err1 == nil, ok2 && err2 == nil
+		if d1.Before(d2) {
+			c = -1
+		} else if d1.After(d2) {
+			c = 1
+		}
+	default:
+		c = strings.Compare(v1, v2)
+	}
+
+	if !ok1 || !ok2 {
+		return ok1 && !ok2
+	}
+	if st.desc {
+		return c > 0
+	}
+	return c < 0
+}
+
+// tagValue returns the value of the additional tag of the task, or the formatted DueDate and ThresholdDate for the known date tags "due" and "t".
+func tagValue(task *Task, key string) (string, bool) {
+	if key == "due" || key == "t" {
+		date := task.DueDate
+		if key == "t" {
+			date = task.ThresholdDate
+		}
+		return dateValue(date), !date.IsZero()
+	}
+	value, found := task.AdditionalTags[key]
+	return value, found
+}
+
 type tasklistSort struct {
 	tasklists TaskList
 	by        func(t1, t2 *Task) bool
@@ -83,12 +222,32 @@ func (ts *tasklistSort) Less(l, r int) bool {
 	return ts.by(&ts.tasklists[l], &ts.tasklists[r])
 }
 
+// sections returns the index ranges [start, end) of consecutive tasks between comment and blank lines of the TaskList.
+func (tasklist TaskList) sections() [][2]int {
+	var ranges [][2]int
+	start := -1
+	for i := 0; i <= len(tasklist); i++ {
+		if i < len(tasklist) && tasklist[i].IsTask() {
+			if start < 0 {
+				start = i
+			}
+		} else if start >= 0 {
+			ranges = append(ranges, [2]int{start, i})
+			start = -1
+		}
+	}
+	return ranges
+}
+
 func (tasklist *TaskList) sortBy(by func(t1, t2 *Task) bool) *TaskList {
-	ts := &tasklistSort{
-		tasklists: *tasklist,
-		by:        by,
+	// Sort tasks of each section, and leave other lines where they are
+	for _, r := range tasklist.sections() {
+		ts := &tasklistSort{
+			tasklists: (*tasklist)[r[0]:r[1]],
+			by:        by,
+		}
+		sort.Stable(ts)
 	}
-	sort.Stable(ts)
 	return tasklist
 }
 
@@ -164,6 +323,13 @@ func (tasklist *TaskList) sortByDueDate(order TaskSortByType) *TaskList {
 	return tasklist
 }
 
+func (tasklist *TaskList) sortByThresholdDate(order TaskSortByType) *TaskList {
+	tasklist.sortBy(func(t1, t2 *Task) bool {
+		return sortByDate(order == SortThresholdDateAsc, t1.HasThresholdDate(), t2.HasThresholdDate(), t1.ThresholdDate, t2.ThresholdDate)
+	})
+	return tasklist
+}
+
 // lessStrings checks if the string slices a is exactly less than b in lexicographical order.
 func lessStrings(a, b []string) bool {
 	la, lb, min := len(a), len(b), 0
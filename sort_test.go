@@ -2,6 +2,7 @@ package todotxt
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -34,6 +35,10 @@ func TestTaskSortByType(t *testing.T) {
 		SortContextDesc:       "ContextDesc",
 		SortProjectAsc:        "ProjectAsc",
 		SortProjectDesc:       "ProjectDesc",
+		SortThresholdDateAsc:  "ThresholdDateAsc",
+		SortThresholdDateDesc: "ThresholdDateDesc",
+		SortUrgencyAsc:        "UrgencyAsc",
+		SortUrgencyDesc:       "UrgencyDesc",
 		0:                     "TaskSortByType(0)",
 	}
 	for n, s := range names {
@@ -181,6 +186,44 @@ func TestTaskSortByDueDate(t *testing.T) {
 	checkTaskListOrder(t, testTasklist, testExpectedList)
 }
 
+func TestTaskSortByThresholdDate(t *testing.T) {
+	testTasklist = TaskList{}
+	for _, text := range []string{
+		"Create golang library @Go t:2014-01-05",
+		"Create golang library test cases @Go",
+		"Outline chapter 5 +Novel t:2014-02-17",
+		"Create golang library documentation @Go t:2014-01-12",
+	} {
+		task, err := ParseTask(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testTasklist.AddTask(task)
+	}
+
+	if err := testTasklist.Sort(SortThresholdDateAsc); err != nil {
+		t.Fatal(err)
+	}
+	testExpectedList = []string{
+		"Create golang library test cases @Go",
+		"Create golang library @Go t:2014-01-05",
+		"Create golang library documentation @Go t:2014-01-12",
+		"Outline chapter 5 +Novel t:2014-02-17",
+	}
+	checkTaskListOrder(t, testTasklist, testExpectedList)
+
+	if err := testTasklist.Sort(SortThresholdDateDesc); err != nil {
+		t.Fatal(err)
+	}
+	testExpectedList = []string{
+		"Outline chapter 5 +Novel t:2014-02-17",
+		"Create golang library documentation @Go t:2014-01-12",
+		"Create golang library @Go t:2014-01-05",
+		"Create golang library test cases @Go",
+	}
+	checkTaskListOrder(t, testTasklist, testExpectedList)
+}
+
 func TestTaskSortByTaskID(t *testing.T) {
 	if err := testTasklist.LoadFromPath(testInputSort); err != nil {
 		t.Fatal(err)
@@ -399,6 +442,86 @@ func TestTaskSortByMultipleFlags(t *testing.T) {
 	checkTaskListOrder(t, testTasklist, testExpectedList)
 }
 
+func TestTaskSortByCustomKeys(t *testing.T) {
+	tasklist, err := LoadFromReader(strings.NewReader(`(B) Task 1 est:10 owner:bob
+(A) Task 2 est:2.5 t:2020-03-01
+Task 3 est:x owner:alice t:2020-01-01
+(A) Task 4 owner:carol review:2020-02-01
+Task 5 est:2.5 review:2020-01-15
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := func() string {
+		var ids []int
+		for _, task := range tasklist {
+			ids = append(ids, task.ID)
+		}
+		return fmt.Sprint(ids)
+	}
+
+	for _, tc := range []struct {
+		keys     []SortKey
+		expected string
+	}{
+		{[]SortKey{SortTagAsc("est", TagNumber)}, "[2 5 1 3 4]"},
+		{[]SortKey{SortTagDesc("est", TagNumber)}, "[1 2 5 3 4]"},
+		{[]SortKey{SortTagAsc("owner", TagString)}, "[3 1 4 2 5]"},
+		{[]SortKey{SortTagDesc("owner", TagString)}, "[4 1 3 2 5]"},
+		{[]SortKey{SortTagAsc("t", TagDate), SortTaskIDAsc}, "[3 2 1 4 5]"},
+		{[]SortKey{SortTagDesc("review", TagDate), SortTaskIDDesc}, "[4 5 3 2 1]"},
+		{[]SortKey{SortPriorityAsc, SortTagAsc("est", TagNumber), SortTaskIDAsc}, "[2 4 1 5 3]"},
+		{[]SortKey{SortFunc(func(t1, t2 *Task) int { return len(t2.AdditionalTags) - len(t1.AdditionalTags) }), SortTodoTextDesc}, "[5 4 3 1 2]"},
+		{[]SortKey{SortFunc(func(t1, t2 *Task) int { return t1.ID%2 - t2.ID%2 }), SortTaskIDAsc}, "[2 4 1 3 5]"},
+	} {
+		if err := tasklist.SortBy(tc.keys[0], tc.keys[1:]...); err != nil {
+			t.Fatal(err)
+		}
+		testExpected = tc.expected
+		testGot = ids()
+		if testGot != testExpected {
+			t.Errorf("Expected tasks to be sorted as %s by %v, but got %s", testExpected, tc.keys, testGot)
+		}
+	}
+
+	if err := tasklist.SortBy(SortFunc(nil)); err == nil {
+		t.Errorf("Expected SortBy() to fail because of nil sort function, but it didn't!")
+	}
+	if err := tasklist.SortBy(SortTagAsc("est", TagType(9))); err == nil {
+		t.Errorf("Expected SortBy() to fail because of unrecognized tag type, but it didn't!")
+	}
+	if err := tasklist.SortBy(SortTodoTextAsc, TaskSortByType(123)); err == nil {
+		t.Errorf("Expected SortBy() to fail because of unrecognized sort option, but it didn't!")
+	}
+}
+
+func TestTaskSortSections(t *testing.T) {
+	IgnoreComments, PreserveComments = true, true
+	defer func() { PreserveComments = false }()
+	tasklist, err := LoadFromReader(strings.NewReader("# Work\nd work\nb work\n\n# Home\nc home\na home\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tasklist.Sort(SortTodoTextAsc); err != nil {
+		t.Fatal(err)
+	}
+	testExpected = "# Work\nb work\nd work\n\n# Home\na home\nc home\n"
+	testGot = tasklist.String()
+	if testGot != testExpected {
+		t.Errorf("Expected sections to be sorted on their own as [%s], but got [%s]", testExpected, testGot)
+	}
+
+	if err := tasklist.Sort(SortTodoTextDesc); err != nil {
+		t.Fatal(err)
+	}
+	testExpected = "# Work\nd work\nb work\n\n# Home\nc home\na home\n"
+	testGot = tasklist.String()
+	if testGot != testExpected {
+		t.Errorf("Expected sections to be sorted on their own as [%s], but got [%s]", testExpected, testGot)
+	}
+}
+
 func TestTaskSortError(t *testing.T) {
 	if err := testTasklist.LoadFromPath(testInputSort); err != nil {
 		t.Fatal(err)
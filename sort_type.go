@@ -24,11 +24,15 @@ func _() {
 	_ = x[SortContextDesc-14]
 	_ = x[SortProjectAsc-15]
 	_ = x[SortProjectDesc-16]
+	_ = x[SortThresholdDateAsc-17]
+	_ = x[SortThresholdDateDesc-18]
+	_ = x[SortUrgencyAsc-19]
+	_ = x[SortUrgencyDesc-20]
 }
 
-const _TaskSortByType_name = "TaskIDAscTaskIDDescTodoTextAscTodoTextDescPriorityAscPriorityDescCreatedDateAscCreatedDateDescCompletedDateAscCompletedDateDescDueDateAscDueDateDescContextAscContextDescProjectAscProjectDesc"
+const _TaskSortByType_name = "TaskIDAscTaskIDDescTodoTextAscTodoTextDescPriorityAscPriorityDescCreatedDateAscCreatedDateDescCompletedDateAscCompletedDateDescDueDateAscDueDateDescContextAscContextDescProjectAscProjectDescThresholdDateAscThresholdDateDescUrgencyAscUrgencyDesc"
 
-var _TaskSortByType_index = [...]uint8{0, 9, 19, 30, 42, 53, 65, 79, 94, 110, 127, 137, 148, 158, 169, 179, 190}
+var _TaskSortByType_index = [...]uint8{0, 9, 19, 30, 42, 53, 65, 79, 94, 110, 127, 137, 148, 158, 169, 179, 190, 206, 223, 233, 244}
 
 func (i TaskSortByType) String() string {
 	i -= 1
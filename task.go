@@ -12,35 +12,64 @@ var (
 	// DateLayout is used for formatting time.Time into todo.txt date format and vice-versa.
 	DateLayout = "2006-01-02"
 
-	priorityRx = regexp.MustCompile(`^(x|x \d{4}-\d{2}-\d{2}|)\s*\(([A-Z])\)\s+`) // Match priority: '(A) ...' or 'x (A) ...' or 'x 2012-12-12 (A) ...'
+	priorityRx = regexp.MustCompile(`^(x|x \d{4}-\d{2}-\d{2}(?:T\d{2}:\d{2})?|)\s*\(([A-Z])\)\s+`) // Match priority: '(A) ...' or 'x (A) ...' or 'x 2012-12-12 (A) ...'
 	// Match created date: '(A) 2012-12-12 ...' or 'x 2012-12-12 (A) 2012-12-12 ...' or 'x (A) 2012-12-12 ...'or 'x 2012-12-12 2012-12-12 ...' or '2012-12-12 ...'
-	createdDateRx   = regexp.MustCompile(`^(\([A-Z]\)|x \d{4}-\d{2}-\d{2} \([A-Z]\)|x \([A-Z]\)|x \d{4}-\d{2}-\d{2}|)\s*(\d{4}-\d{2}-\d{2})\s+`)
-	completedRx     = regexp.MustCompile(`^x\s+`)                       // Match completed: 'x ...'
-	completedDateRx = regexp.MustCompile(`^x\s*(\d{4}-\d{2}-\d{2})\s+`) // Match completed date: 'x 2012-12-12 ...'
-	addonTagRx      = regexp.MustCompile(`(^|\s+)([^:\s]+):([^:\s]+)`)  // Match additional tags date: '... due:2012-12-12 ...'
-	contextRx       = regexp.MustCompile(`(^|\s+)@(\S+)`)               // Match contexts: '@Context ...' or '... @Context ...'
-	projectRx       = regexp.MustCompile(`(^|\s+)\+(\S+)`)              // Match projects: '+Project...' or '... +Project ...')
+	createdDateRx   = regexp.MustCompile(`^(\([A-Z]\)|x \d{4}-\d{2}-\d{2}(?:T\d{2}:\d{2})? \([A-Z]\)|x \([A-Z]\)|x \d{4}-\d{2}-\d{2}(?:T\d{2}:\d{2})?|)\s*(\d{4}-\d{2}-\d{2})\s+`)
+	completedRx     = regexp.MustCompile(`^x\s+`)                                        // Match completed: 'x ...'
+	completedDateRx = regexp.MustCompile(`^x\s*(\d{4}-\d{2}-\d{2}(?:T\d{2}:\d{2})?)\s+`) // Match completed date: 'x 2012-12-12 ...' or 'x 2012-12-12T17:00 ...'
+	addonTagRx      = regexp.MustCompile(`(^|\s+)([^:\s]+):([^:\s]+(?::\d{2})*)`)        // Match additional tags date: '... due:2012-12-12 ...' or '... time:17:00 ...'
+	contextRx       = regexp.MustCompile(`(^|\s+)@(\S+)`)                                // Match contexts: '@Context ...' or '... @Context ...'
+	projectRx       = regexp.MustCompile(`(^|\s+)\+(\S+)`)                               // Match projects: '+Project...' or '... +Project ...')
+)
+
+// LineKind represents kind of a line in todo.txt file.
+//go:generate stringer -type LineKind -trimprefix Line -output line_kind.go
+type LineKind uint8
+
+// Flags for indicating kind of line in todo.txt file.
+const (
+	LineTask     LineKind = iota // Line of a task.
+	LineComment                  // Comment line starting with "#".
+	LineBlank                    // Blank line.
+	LineUnparsed                 // Malformed task line kept by LenientLoading.
 )
 
 // Task represents a todo.txt task entry.
+//
+// Comment and blank lines kept by PreserveComments, and malformed lines kept by LenientLoading are also represented as Task entries,
+// with Kind set to LineComment, LineBlank or LineUnparsed, and the raw line text in Original. They have no ID and are skipped by filtering and sorting.
 type Task struct {
-	ID             int    // Internal task ID.
-	Original       string // Original raw task text.
-	Todo           string // Todo part of task text.
+	ID             int      // Internal task ID.
+	Kind           LineKind // Kind of line, LineTask for all parsed tasks.
+	Original       string   // Original raw task text.
+	Todo           string   // Todo part of task text.
 	Priority       string
 	Projects       []string
 	Contexts       []string
 	AdditionalTags map[string]string // Addon tags will be available here.
 	CreatedDate    time.Time
 	DueDate        time.Time
+	ThresholdDate  time.Time // Threshold date (also known as start date) given by "t:" tag, the task is hidden until then.
 	CompletedDate  time.Time
 	Completed      bool
+
+	clock         Clock           // Clock for time-relative methods, SystemClock is used if it's nil.
+	loc           *time.Location  // Location for calendar days of time-relative methods, Location is used if it's nil.
+	indent        string          // Leading whitespace of the parsed task text, for TaskList.TreeByIndent() and writing the TaskList.
+	dueTime       bool            // DueDate has time of day.
+	completedTime bool            // CompletedDate has time of day.
+	parsed        *parsedOriginal // Task parsed from Original, for formatting with PreserveFormat.
 }
 
 // NewTask creates a new empty Task with default values. (CreatedDate is set to Now())
 func NewTask() Task {
-	task := Task{}
-	task.CreatedDate = time.Now()
+	return defaultParser().NewTask()
+}
+
+// NewTask creates a new empty Task with default values and the Clock of the parser. (CreatedDate is set to Now() of the Clock)
+func (p *Parser) NewTask() Task {
+	task := Task{clock: p.opts.Clock, loc: p.opts.Location}
+	task.CreatedDate = task.now()
 	return task
 }
 
@@ -54,28 +83,36 @@ func (task *Task) Task() string {
 //
 // Contexts, Projects and additional tags are alphabetically sorted,
 // and appended at the end in the following order:
-// Contexts, Projects, Tags
+// Contexts, Projects, Tags, Threshold date, Due date
 //
 // For example:
 //  "(A) 2013-07-23 Call Dad @Home @Phone +Family due:2013-07-31 customTag1:Important!"
+//
+// If PreserveFormat is set to 'true' and the task has an Original text, the original token order and spacing are kept instead.
+// See PreserveFormat for further information.
+//
+// The package-level variables are used as options, see Parser.Format() for formatting with other options.
 func (task Task) String() string {
-	var sb strings.Builder
+	return defaultParser().Format(&task)
+}
 
-	if task.Completed {
-		sb.WriteString("x ")
-		if task.HasCompletedDate() {
-			sb.WriteString(fmt.Sprintf("%s ", task.CompletedDate.Format(DateLayout)))
-		}
+// Format returns a complete task string in todo.txt format with options of the parser.
+// See Task.String() for further information.
+func (p *Parser) Format(task *Task) string {
+	if !task.IsTask() {
+		return task.Original
 	}
-
-	if task.HasPriority() && (!task.Completed || !RemoveCompletedPriority) {
-		sb.WriteString(fmt.Sprintf("(%s) ", task.Priority))
+	if p.opts.PreserveFormat && isNotEmpty(task.Original) {
+		return p.preservedString(task)
 	}
+	return p.canonicalString(task)
+}
 
-	if task.HasCreatedDate() {
-		sb.WriteString(fmt.Sprintf("%s ", task.CreatedDate.Format(DateLayout)))
-	}
+// canonicalString returns the task string in todo.txt format, rebuilt from the fields in a fixed order.
+func (p *Parser) canonicalString(task *Task) string {
+	var sb strings.Builder
 
+	sb.WriteString(p.prefixString(task))
 	sb.WriteString(task.Todo)
 
 	if task.HasContexts() {
@@ -93,42 +130,110 @@ func (task Task) String() string {
 	}
 
 	if task.HasAdditionalTags() {
-		// Sort map alphabetically by keys
-		keys := make([]string, 0, len(task.AdditionalTags))
-		for key := range task.AdditionalTags {
-			keys = append(keys, key)
-		}
-		sort.Strings(keys)
-		for _, key := range keys {
+		for _, key := range task.sortedTagKeys() {
 			sb.WriteString(fmt.Sprintf(" %s:%s", key, task.AdditionalTags[key]))
 		}
 	}
 
+	if task.HasThresholdDate() {
+		sb.WriteString(fmt.Sprintf(" t:%s", p.formatTime(task.ThresholdDate)))
+	}
+
 	if task.HasDueDate() {
-		sb.WriteString(fmt.Sprintf(" due:%s", task.DueDate.Format(DateLayout)))
+		sb.WriteString(fmt.Sprintf(" due:%s", p.formatDueDate(task)))
+	}
+
+	return sb.String()
+}
+
+// prefixString returns the leading part of the task string, i.e. completion mark, completed date, priority and created date, each followed by a space.
+func (p *Parser) prefixString(task *Task) string {
+	var sb strings.Builder
+
+	if task.Completed {
+		sb.WriteString("x ")
+		if task.HasCompletedDate() {
+			sb.WriteString(fmt.Sprintf("%s ", p.formatCompletedDate(task)))
+		}
+	}
+
+	if task.HasPriority() && (!task.Completed || !p.opts.RemoveCompletedPriority) {
+		sb.WriteString(fmt.Sprintf("(%s) ", task.Priority))
+	}
+
+	if task.HasCreatedDate() {
+		sb.WriteString(fmt.Sprintf("%s ", p.formatTime(task.CreatedDate)))
 	}
 
 	return sb.String()
 }
 
+// sortedTagKeys returns the keys of additional tags in alphabetical order.
+func (task *Task) sortedTagKeys() []string {
+	keys := make([]string, 0, len(task.AdditionalTags))
+	for key := range task.AdditionalTags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // ParseTask parses the input text string into a Task struct.
+//
+// If any date in the text is invalid, a *ParseError is returned with the column and field of the offending token.
+//
+// The package-level variables are used as options, see Parser.Parse() for parsing with other options.
 func ParseTask(text string) (*Task, error) {
+	return defaultParser().Parse(text)
+}
+
+// Parse parses the input text string into a Task struct with options of the parser.
+// See ParseTask() for further information.
+func (p *Parser) Parse(text string) (*Task, error) {
 	var err error
 
 	oriText := strings.Trim(text, whitespaces)
-	task := Task{}
+	offset := len(text) - len(strings.TrimLeft(text, whitespaces)) // Offset of oriText in text
+	task := Task{clock: p.opts.Clock, loc: p.opts.Location, indent: text[:offset]}
 	task.Original = oriText
 	task.Todo = oriText
 
+	// function for creating ParseError of the submatch with given index in oriText
+	errorAt := func(field TaskSegmentType, loc []int, idx int, err error) error {
+		start, end := loc[2*idx], loc[2*idx+1]
+		return &ParseError{
+			Column: offset + start + 1,
+			Text:   text,
+			Token:  oriText[start:end],
+			Field:  field,
+			Err:    err,
+		}
+	}
+
+	// function for parsing date of the submatch with given index in oriText
+	parseDateAt := func(field TaskSegmentType, loc []int, idx int) (time.Time, error) {
+		parse := p.parseTime
+		if field == SegmentThresholdDate { // only dates of tags can be relative dates
+			parse = p.parseDate
+		}
+		date, err := parse(oriText[loc[2*idx]:loc[2*idx+1]])
+		if err != nil {
+			return date, errorAt(field, loc, idx, err)
+		}
+		return date, nil
+	}
+
 	// Check for completed
+	completedEnd := 0 // End of completed date in oriText, its time of day is not an additional tag
 	if completedRx.MatchString(oriText) {
 		task.Completed = true
 		// Check for completed date
-		if completedDateRx.MatchString(oriText) {
-			if date, err := parseTime(completedDateRx.FindStringSubmatch(oriText)[1]); err == nil {
-				task.CompletedDate = date
+		if loc := completedDateRx.FindStringSubmatchIndex(oriText); loc != nil {
+			if date, hasTime, err := p.parseCompletedDate(oriText[loc[2]:loc[3]]); err == nil {
+				task.CompletedDate, task.completedTime = date, hasTime
+				completedEnd = loc[3]
 			} else {
-				return nil, err
+				return nil, errorAt(SegmentCompletedDate, loc, 1, err)
 			}
 		}
 
@@ -144,13 +249,16 @@ func ParseTask(text string) (*Task, error) {
 	}
 
 	// Check for created date
-	if createdDateRx.MatchString(oriText) {
-		if date, err := parseTime(createdDateRx.FindStringSubmatch(oriText)[2]); err == nil {
+	if loc := createdDateRx.FindStringSubmatchIndex(oriText); loc != nil {
+		if date, err := parseDateAt(SegmentCreatedDate, loc, 2); err == nil {
 			task.CreatedDate = date
 			task.Todo = createdDateRx.ReplaceAllString(task.Todo, emptyStr) // Remove from Todo text
 		} else {
 			return nil, err
 		}
+	} else if loc := createdRelativeRx.FindStringSubmatchIndex(oriText); loc != nil && p.isCreatedRelativeDate(oriText[loc[4]:loc[5]]) {
+		task.CreatedDate, _ = p.parseDate(oriText[loc[4]:loc[5]])
+		task.Todo = createdRelativeRx.ReplaceAllString(task.Todo, emptyStr) // Remove from Todo text
 	}
 
 	// function for collecting projects/contexts as slices from text
@@ -183,13 +291,21 @@ func ParseTask(text string) (*Task, error) {
 
 	// Check for additional tags
 	if addonTagRx.MatchString(oriText) {
-		matches := addonTagRx.FindAllStringSubmatch(oriText, -1)
+		matches := addonTagRx.FindAllStringSubmatchIndex(oriText, -1)
 		tags := make(map[string]string, len(matches))
-		for _, match := range matches {
-			key, value := match[2], match[3]
-			if key == "due" { // due date is a known addon tag, it has its own struct field
-				if date, err := parseTime(value); err == nil {
-					task.DueDate = date
+		for _, loc := range matches {
+			key, value := oriText[loc[4]:loc[5]], oriText[loc[6]:loc[7]]
+			if loc[4] < completedEnd {
+				continue
+			} else if key == "due" { // due date is a known addon tag, it has its own struct field
+				if date, hasTime, err := p.parseDueDate(value); err == nil {
+					task.DueDate, task.dueTime = date, hasTime
+				} else {
+					return nil, errorAt(SegmentDueDate, loc, 3, err)
+				}
+			} else if key == "t" { // threshold date is also a known addon tag
+				if date, err := parseDateAt(SegmentThresholdDate, loc, 3); err == nil {
+					task.ThresholdDate = date
 				} else {
 					return nil, err
 				}
@@ -199,14 +315,25 @@ func ParseTask(text string) (*Task, error) {
 		}
 		task.AdditionalTags = tags
 		task.Todo = addonTagRx.ReplaceAllString(task.Todo, emptyStr) // Remove from Todo text
+		applyTimeTag(&task)
 	}
 
 	// Trim any remaining whitespaces from Todo text
 	task.Todo = strings.Trim(task.Todo, "\t\n\r\f ")
 
+	// Cache the parsed task unless it depends on the current date
+	if !p.opts.RelativeDates || !p.hasRelativeDates(oriText) {
+		task.parsed = &parsedOriginal{text: oriText, layout: p.opts.DateLayout, task: copyTask(&task)}
+	}
+
 	return &task, err
 }
 
+// IsTask returns true if the entry is a task, rather than a comment, blank or unparsed line.
+func (task *Task) IsTask() bool {
+	return task.Kind == LineTask
+}
+
 // HasProjects returns true if the task has any projects.
 func (task *Task) HasProjects() bool {
 	return len(task.Projects) > 0
@@ -243,11 +370,12 @@ func (task *Task) IsCompleted() bool {
 }
 
 // Complete sets Task.Completed to 'true' if the task was not already completed.
-// Also sets Task.CompletedDate to time.Now()
+// Also sets Task.CompletedDate to Now() of the task's Clock, which is written as date only, see SetCompletedTime().
 func (task *Task) Complete() {
 	if !task.Completed {
 		task.Completed = true
-		task.CompletedDate = time.Now()
+		task.CompletedDate = task.now()
+		task.completedTime = false
 	}
 }
 
@@ -257,6 +385,7 @@ func (task *Task) Reopen() {
 	if task.Completed {
 		task.Completed = false
 		task.CompletedDate = time.Time{} // time.IsZero() value
+		task.completedTime = false
 	}
 }
 
@@ -265,30 +394,56 @@ func (task *Task) HasDueDate() bool {
 	return !task.DueDate.IsZero()
 }
 
-// IsOverdue returns true if due date is in the past.
+// IsOverdue returns true if due date is in the past, or due time has passed if the due date has time of day.
+// Dates are compared by calendar days in the task's location, see SetLocation().
 //
 // This function does not take the Completed flag into consideration.
 // You should check Task.Completed first if needed.
 func (task *Task) IsOverdue() bool {
+	if task.HasDueTime() {
+		return task.now().After(task.DueDate)
+	}
 	if task.HasDueDate() {
-		return task.Due() < 0
+		return daysBetween(task.now(), task.DueDate) < 0
 	}
 	return false
 }
 
-// IsDueToday returns true if the task is due todasy.
+// IsDueToday returns true if the task is due today, by calendar days in the task's location, see SetLocation().
+// Tasks due today with passed due time are also overdue.
 func (task *Task) IsDueToday() bool {
 	if task.HasDueDate() {
-		due := task.Due()
-		return 0 < due && due <= oneDay
+		return daysBetween(task.now(), task.DueDate) == 0
 	}
 	return false
 }
 
+// HasThresholdDate returns true if the task has a threshold date.
+func (task *Task) HasThresholdDate() bool {
+	return !task.ThresholdDate.IsZero()
+}
+
+// IsActionable returns true if the task has no threshold date, or the threshold date is today or in the past.
+// The current date is given by the task's Clock, see SetClock().
+//
+// This function does not take the Completed flag into consideration.
+// You should check Task.Completed first if needed.
+func (task *Task) IsActionable() bool {
+	if task.HasThresholdDate() {
+		return daysBetween(task.now(), task.ThresholdDate) <= 0
+	}
+	return true
+}
+
 // Due returns the duration left until due date from now. The duration is negative if the task is overdue.
+// The due date ends at its due time if it has time of day, or at the end of the day otherwise.
+// The current time is given by the task's Clock, see SetClock().
 //
 // Just as with IsOverdue(), this function does also not take the Completed flag into consideration.
 // You should check Task.Completed first if needed.
 func (task *Task) Due() time.Duration {
-	return time.Until(task.DueDate.AddDate(0, 0, 1))
+	if task.HasDueTime() {
+		return task.DueDate.Sub(task.now())
+	}
+	return task.DueDate.AddDate(0, 0, 1).Sub(task.now())
 }
@@ -1,6 +1,7 @@
 package todotxt
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -887,3 +888,88 @@ func TestRemoveCompletedPriority(t *testing.T) {
 
 	RemoveCompletedPriority = false
 }
+
+func TestParseTaskError(t *testing.T) {
+	cases := []struct {
+		text   string
+		column int
+		token  string
+		field  TaskSegmentType
+		errMsg string
+	}{
+		{"x 2020-13-01 Call Mom", 3, "2020-13-01", SegmentCompletedDate, `column 3: invalid completed date: parsing time "2020-13-01": month out of range`},
+		{"(A) 2020-01-32 Call Mom", 5, "2020-01-32", SegmentCreatedDate, `column 5: invalid created date: parsing time "2020-01-32": day out of range`},
+		{"  Call Mom due:2020-13-45 @Phone", 16, "2020-13-45", SegmentDueDate, `column 16: invalid due date: parsing time "2020-13-45": month out of range`},
+	}
+	for _, c := range cases {
+		task, err := ParseTask(c.text)
+		if task != nil {
+			t.Errorf("Expected ParseTask to fail for [%s], but got Task back: [%s]", c.text, task)
+		}
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("Expected ParseTask to fail with ParseError for [%s], but got: %v", c.text, err)
+			continue
+		}
+		if perr.Line != 0 || perr.Column != c.column || perr.Token != c.token || perr.Field != c.field || perr.Text != c.text {
+			t.Errorf("Expected ParseError at column %d for %v [%s], but got: %+v", c.column, c.field, c.token, *perr)
+		}
+		if msg := perr.Error(); msg != c.errMsg {
+			t.Errorf("Expected ParseError message [%s], but got [%s]", c.errMsg, msg)
+		}
+	}
+}
+
+func TestTaskThresholdDate(t *testing.T) {
+	task, err := ParseTask("Plan backyard herb garden t:2014-05-01 @Home due:2014-05-31")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	date, err := parseTime("2014-05-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !task.ThresholdDate.Equal(date) {
+		t.Errorf("Expected Task to have threshold date '%s', but got '%v'", date, task.ThresholdDate)
+	}
+	if _, found := task.AdditionalTags["t"]; found {
+		t.Errorf("Expected threshold date not to be in additional tags, but got %v", task.AdditionalTags)
+	}
+	testExpected = "Plan backyard herb garden @Home t:2014-05-01 due:2014-05-31"
+	testGot = task.String()
+	if testGot != testExpected {
+		t.Errorf("Expected Task to be [%s], but got [%s]", testExpected, testGot)
+	}
+
+	cases := []struct {
+		now        time.Time
+		actionable bool
+	}{
+		{time.Date(2014, 4, 30, 23, 59, 0, 0, time.Local), false},
+		{time.Date(2014, 5, 1, 0, 0, 0, 0, time.Local), true},
+		{time.Date(2014, 5, 2, 12, 0, 0, 0, time.Local), true},
+	}
+	for _, c := range cases {
+		task.SetClock(FixedClock(c.now))
+		if got := task.IsActionable(); got != c.actionable {
+			t.Errorf("Expected IsActionable() at [%v] to be %v, but got %v", c.now, c.actionable, got)
+		}
+	}
+
+	task, err = ParseTask("Plan backyard herb garden @Home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.HasThresholdDate() || !task.IsActionable() {
+		t.Errorf("Expected Task without threshold date to be actionable")
+	}
+
+	task, err = ParseTask("Plan backyard herb garden t:2014-02-30")
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Field != SegmentThresholdDate || perr.Column != 29 {
+		t.Errorf("Expected ParseTask to fail with ParseError for threshold date, but got: %v", err)
+	} else if msg := perr.Error(); msg != `column 29: invalid threshold date: parsing time "2014-02-30": day out of range` {
+		t.Errorf("Expected ParseError message, but got [%s]", msg)
+	}
+}
@@ -3,9 +3,10 @@ package todotxt
 import (
 	"bufio"
 	"errors"
-	"io/ioutil"
+	"io"
 	"os"
 	"strings"
+	"time"
 
 	ys "github.com/1set/gut/ystring"
 )
@@ -16,6 +17,9 @@ type TaskList []Task
 
 // IgnoreComments can be set to 'false', in order to revert to a more standard todo.txt behaviour.
 // The todo.txt format does not define comments.
+//
+// These variables are the default options for package-level functions and methods of Task and TaskList.
+// Use a Parser with Options for different settings per use, as changing the variables is not safe for concurrent use.
 var (
 	// IgnoreComments is used to switch ignoring of comments (lines starting with "#").
 	// If this is set to 'false', then lines starting with "#" will be parsed as tasks.
@@ -24,6 +28,44 @@ var (
 	// RemoveCompletedPriority is used to switch discarding priority on task completion like many todo.txt clients do.
 	// If this is set to 'false', then the priority of completed task will be kept as it is.
 	RemoveCompletedPriority = true
+
+	// PreserveFormat is used to switch lossless round-trip of task strings.
+	// If this is set to 'true', then unchanged tasks are written back exactly as their Task.Original text,
+	// and edited tasks only get the modified tokens rewritten, keeping the original token order and spacing.
+	PreserveFormat = false
+
+	// PreserveComments is used to switch keeping of comment and blank lines in TaskList.
+	// If this is set to 'true', then blank lines and comment lines (if IgnoreComments is 'true') are kept as entries of kind LineBlank and LineComment,
+	// and written back in their original positions. Filtering, sorting and ID assignment skip these entries.
+	PreserveComments = false
+
+	// PreserveIndent is used to switch writing back the leading whitespace of tasks in TaskList, e.g. of subtasks for TaskList.TreeByIndent().
+	// If this is set to 'true', then tasks loaded or parsed from indented text are written with their original indentation.
+	// Sorting moves tasks away from their parents, so the indentation of a sorted TaskList doesn't give the same tree anymore.
+	PreserveIndent = false
+
+	// LenientLoading is used to switch loading of TaskList with malformed lines.
+	// If this is set to 'true', then loading doesn't stop on the first error: malformed lines are kept as entries of kind LineUnparsed
+	// with the raw line text, and all errors are returned together as ParseErrors along with the loaded TaskList.
+	LenientLoading = false
+
+	// RelativeDates is used to switch resolving of relative and natural dates in due date, threshold date and created date.
+	// If this is set to 'true', then dates of "due:" and "t:" tags like "due:tomorrow", "due:fri", "t:+3d" or "due:eow"
+	// are resolved into absolute dates when parsing, relative to the current date of the Clock. See ParseRelativeDate() for the syntax.
+	// Created dates can only be "today" or "yesterday" in lowercase followed by todo text, e.g. "(A) today Call Mom",
+	// so other words like "Friday" at the beginning of todo text are kept. Completed dates are never relative.
+	// The dates are written in DateLayout, unless PreserveFormat keeps the original text of unchanged tokens.
+	RelativeDates = false
+
+	// RewriteRelativeDates is used to switch rewriting of relative dates resolved by RelativeDates when formatting with PreserveFormat.
+	// If this is set to 'true', then relative dates in Task.Original are written as absolute dates in DateLayout, e.g. on save,
+	// instead of keeping the original text which would be resolved to another date later.
+	RewriteRelativeDates = false
+
+	// Location is used for parsing dates and comparing calendar days, e.g. in IsOverdue(), IsDueToday() and filters by dates.
+	// If this is set to a fixed location like time.UTC, then tasks get the same results on servers in different time zones.
+	// If this is nil, time.Local is used.
+	Location = time.Local
 )
 
 // NewTaskList creates a new empty TaskList.
@@ -31,23 +73,44 @@ func NewTaskList() TaskList {
 	return TaskList{}
 }
 
-// String returns a complete list of tasks in todo.txt format.
+// String returns a complete list of tasks in todo.txt format. Tasks keep the leading whitespace of the parsed text if PreserveIndent is set.
 func (tasklist TaskList) String() string {
+	return defaultParser().formatList(tasklist)
+}
+
+// formatList returns a complete list of tasks in todo.txt format with options of the parser.
+func (p *Parser) formatList(tasklist TaskList) string {
 	var sb strings.Builder
-	for _, task := range tasklist {
-		sb.WriteString(task.String())
+	for i := range tasklist {
+		sb.WriteString(p.formatLine(&tasklist[i]))
 		sb.WriteString(ys.NewLine)
 	}
 	return sb.String()
 }
 
+// formatLine returns the entry as a line of todo.txt file with options of the parser,
+// with the leading whitespace of the parsed task text if PreserveIndent is enabled.
+func (p *Parser) formatLine(task *Task) string {
+	if p.opts.PreserveIndent {
+		return task.indent + p.Format(task)
+	}
+	return p.Format(task)
+}
+
 // AddTask appends a Task to the current TaskList and takes care to set the Task.ID correctly, modifying the Task by the given pointer!
+// If the Task has no Clock or location, it gets the ones of the tasks in the TaskList, see SetClock() and SetLocation().
 func (tasklist *TaskList) AddTask(task *Task) {
 	task.ID = 0
 	for _, t := range *tasklist {
 		if t.ID > task.ID {
 			task.ID = t.ID
 		}
+		if task.clock == nil {
+			task.clock = t.clock
+		}
+		if task.loc == nil {
+			task.loc = t.loc
+		}
 	}
 	task.ID++
 
@@ -58,7 +121,7 @@ func (tasklist *TaskList) AddTask(task *Task) {
 // Returns an error if Task could not be found.
 func (tasklist *TaskList) GetTask(id int) (*Task, error) {
 	for i := range *tasklist {
-		if ([]Task(*tasklist))[i].ID == id {
+		if t := &([]Task(*tasklist))[i]; t.IsTask() && t.ID == id {
 			return &([]Task(*tasklist))[i], nil
 		}
 	}
@@ -72,7 +135,7 @@ func (tasklist *TaskList) RemoveTaskByID(id int) error {
 
 	found := false
 	for _, t := range *tasklist {
-		if t.ID != id {
+		if !t.IsTask() || t.ID != id {
 			newList = append(newList, t)
 		} else {
 			found = true
@@ -93,7 +156,7 @@ func (tasklist *TaskList) RemoveTask(task Task) error {
 
 	found := false
 	for _, t := range *tasklist {
-		if t.String() != task.String() {
+		if !t.IsTask() || t.String() != task.String() {
 			newList = append(newList, t)
 		} else {
 			found = true
@@ -107,27 +170,66 @@ func (tasklist *TaskList) RemoveTask(task Task) error {
 	return nil
 }
 
-// LoadFromFile loads a TaskList from *os.File.
+// LoadFromReader loads a TaskList from io.Reader.
 //
-// Using *os.File instead of a filename allows to also use os.Stdin.
+// If a task can't be parsed, a *ParseError is returned with the line number of the task.
+// If LenientLoading is set to 'true', all malformed lines are kept and ParseErrors is returned after loading the whole TaskList.
 //
-// Note: This will clear the current TaskList and overwrite it's contents with whatever is in *os.File.
-func (tasklist *TaskList) LoadFromFile(file *os.File) error {
+// Note: This will clear the current TaskList and overwrite it's contents with whatever is read from io.Reader.
+//
+// The package-level variables are used as options, see Parser.Load() for loading with other options.
+func (tasklist *TaskList) LoadFromReader(reader io.Reader) error {
+	return defaultParser().loadInto(tasklist, reader)
+}
+
+// Load loads and returns a TaskList from io.Reader with options of the parser.
+// See TaskList.LoadFromReader() for further information.
+func (p *Parser) Load(reader io.Reader) (TaskList, error) {
+	tasklist := TaskList{}
+	if err := p.loadInto(&tasklist, reader); err != nil {
+		return failedTaskList(tasklist, err)
+	}
+	return tasklist, nil
+}
+
+// loadInto clears the given TaskList and loads tasks from io.Reader into it.
+func (p *Parser) loadInto(tasklist *TaskList, reader io.Reader) error {
 	*tasklist = []Task{} // Empty task list
 
-	taskID := 1
-	scanner := bufio.NewScanner(file)
+	var parseErrs ParseErrors
+	taskID, lineNum := 1, 0
+	scanner := bufio.NewScanner(reader)
 	for scanner.Scan() {
-		text := strings.Trim(scanner.Text(), whitespaces) // Read line
+		line := scanner.Text()
+		lineNum++
+		text := strings.Trim(line, whitespaces) // Read line
 
-		// Ignore blank or comment lines
-		if isEmpty(text) || (IgnoreComments && strings.HasPrefix(text, "#")) {
+		// Ignore blank or comment lines, or keep them as they are
+		if isEmpty(text) {
+			if p.opts.PreserveComments {
+				*tasklist = append(*tasklist, Task{Kind: LineBlank, Original: line})
+			}
+			continue
+		} else if p.opts.IgnoreComments && strings.HasPrefix(text, "#") {
+			if p.opts.PreserveComments {
+				*tasklist = append(*tasklist, Task{Kind: LineComment, Original: line})
+			}
 			continue
 		}
 
-		task, err := ParseTask(text)
+		task, err := p.Parse(line)
 		if err != nil {
-			return err
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				return err
+			}
+			perr.Line = lineNum
+			if !p.opts.LenientLoading {
+				return err
+			}
+			parseErrs = append(parseErrs, perr)
+			*tasklist = append(*tasklist, Task{Kind: LineUnparsed, Original: line})
+			continue
 		}
 		task.ID = taskID
 
@@ -135,38 +237,106 @@ func (tasklist *TaskList) LoadFromFile(file *os.File) error {
 		taskID++
 	}
 
-	return scanner.Err()
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if len(parseErrs) > 0 {
+		return parseErrs
+	}
+	return nil
+}
+
+// WriteTo writes a TaskList to io.Writer, and returns the number of bytes written.
+//
+// Note: Comments from original file will be omitted and not written to target io.Writer, if IgnoreComments is set to 'true' and PreserveComments is set to 'false'.
+func (tasklist *TaskList) WriteTo(writer io.Writer) (int64, error) {
+	n, err := io.WriteString(writer, tasklist.String())
+	return int64(n), err
+}
+
+// Write writes a TaskList to io.Writer with options of the parser.
+// See TaskList.WriteTo() for further information.
+func (p *Parser) Write(writer io.Writer, tasklist TaskList) error {
+	_, err := io.WriteString(writer, p.formatList(tasklist))
+	return err
+}
+
+// LoadFromFile loads a TaskList from *os.File.
+//
+// Using *os.File instead of a filename allows to also use os.Stdin.
+//
+// Note: This will clear the current TaskList and overwrite it's contents with whatever is in *os.File.
+func (tasklist *TaskList) LoadFromFile(file *os.File) error {
+	return tasklist.LoadFromReader(file)
 }
 
 // WriteToFile writes a TaskList to *os.File.
 //
 // Using *os.File instead of a filename allows to also use os.Stdout.
 //
-// Note: Comments from original file will be omitted and not written to target *os.File, if IgnoreComments is set to 'true'.
+// Note: Comments from original file will be omitted and not written to target *os.File, if IgnoreComments is set to 'true' and PreserveComments is set to 'false'.
 func (tasklist *TaskList) WriteToFile(file *os.File) error {
-	writer := bufio.NewWriter(file)
-	if _, err := writer.WriteString(tasklist.String()); err != nil {
-		return err
-	}
-	return writer.Flush()
+	_, err := tasklist.WriteTo(file)
+	return err
 }
 
 // LoadFromPath loads a TaskList from a file (most likely called "todo.txt").
 //
 // Note: This will clear the current TaskList and overwrite it's contents with whatever is in the file.
 func (tasklist *TaskList) LoadFromPath(filename string) error {
+	return defaultParser().loadPathInto(tasklist, filename)
+}
+
+// LoadFromPath loads and returns a TaskList from a file (most likely called "todo.txt") with options of the parser.
+// See TaskList.LoadFromPath() for further information.
+func (p *Parser) LoadFromPath(filename string) (TaskList, error) {
+	tasklist := TaskList{}
+	if err := p.loadPathInto(&tasklist, filename); err != nil {
+		return failedTaskList(tasklist, err)
+	}
+	return tasklist, nil
+}
+
+// loadPathInto clears the given TaskList and loads tasks from the file into it.
+func (p *Parser) loadPathInto(tasklist *TaskList, filename string) error {
 	file, err := os.Open(filename)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
-	return tasklist.LoadFromFile(file)
+	return p.loadInto(tasklist, file)
 }
 
 // WriteToPath writes a TaskList to the specified file (most likely called "todo.txt").
+//
+// The file is replaced atomically: the TaskList is written to a temporary file in the same directory, synced to disk and renamed to the file.
+// The file mode of an existing file is kept, and new files are created with mode 0640.
 func (tasklist *TaskList) WriteToPath(filename string) error {
-	return ioutil.WriteFile(filename, []byte(tasklist.String()), 0640)
+	return defaultParser().WriteToPath(filename, *tasklist)
+}
+
+// WriteToPath writes a TaskList to the specified file (most likely called "todo.txt") with options of the parser.
+// See TaskList.WriteToPath() for further information.
+func (p *Parser) WriteToPath(filename string, tasklist TaskList) error {
+	return writeFileAtomic(filename, []byte(p.formatList(tasklist)))
+}
+
+// LoadFromReader loads and returns a TaskList from io.Reader.
+func LoadFromReader(reader io.Reader) (TaskList, error) {
+	tasklist := TaskList{}
+	if err := tasklist.LoadFromReader(reader); err != nil {
+		return failedTaskList(tasklist, err)
+	}
+	return tasklist, nil
+}
+
+// WriteTo writes a TaskList to io.Writer.
+//
+// Note: Comments from original file will be omitted and not written to target io.Writer, if IgnoreComments is set to 'true' and PreserveComments is set to 'false'.
+func WriteTo(tasklist *TaskList, writer io.Writer) error {
+	_, err := tasklist.WriteTo(writer)
+	return err
 }
 
 // LoadFromFile loads and returns a TaskList from *os.File.
@@ -175,7 +345,7 @@ func (tasklist *TaskList) WriteToPath(filename string) error {
 func LoadFromFile(file *os.File) (TaskList, error) {
 	tasklist := TaskList{}
 	if err := tasklist.LoadFromFile(file); err != nil {
-		return nil, err
+		return failedTaskList(tasklist, err)
 	}
 	return tasklist, nil
 }
@@ -184,7 +354,7 @@ func LoadFromFile(file *os.File) (TaskList, error) {
 //
 // Using *os.File instead of a filename allows to also use os.Stdout.
 //
-// Note: Comments from original file will be omitted and not written to target *os.File, if IgnoreComments is set to 'true'.
+// Note: Comments from original file will be omitted and not written to target *os.File, if IgnoreComments is set to 'true' and PreserveComments is set to 'false'.
 func WriteToFile(tasklist *TaskList, file *os.File) error {
 	return tasklist.WriteToFile(file)
 }
@@ -193,7 +363,7 @@ func WriteToFile(tasklist *TaskList, file *os.File) error {
 func LoadFromPath(filename string) (TaskList, error) {
 	tasklist := TaskList{}
 	if err := tasklist.LoadFromPath(filename); err != nil {
-		return nil, err
+		return failedTaskList(tasklist, err)
 	}
 	return tasklist, nil
 }
@@ -202,3 +372,13 @@ func LoadFromPath(filename string) (TaskList, error) {
 func WriteToPath(tasklist *TaskList, filename string) error {
 	return tasklist.WriteToPath(filename)
 }
+
+// failedTaskList returns the result of package-level loading functions for the given error.
+// The loaded TaskList is only kept for ParseErrors from LenientLoading.
+func failedTaskList(tasklist TaskList, err error) (TaskList, error) {
+	var perrs ParseErrors
+	if errors.As(err, &perrs) {
+		return tasklist, err
+	}
+	return nil, err
+}
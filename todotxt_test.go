@@ -1,12 +1,21 @@
 package todotxt
 
 import (
+	"bytes"
+	"errors"
 	"io/ioutil"
 	"os"
+	"strings"
 	"testing"
 	"time"
 )
 
+type failingReader struct{}
+
+func (failingReader) Read(p []byte) (int, error) {
+	return 0, errors.New("read error")
+}
+
 func BenchmarkLoadFromPath(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		_, _ = LoadFromPath(testInputTasklist)
@@ -67,6 +76,62 @@ func TestLoadFromPath(t *testing.T) {
 	}
 }
 
+func TestLoadFromReader(t *testing.T) {
+	data, err := ioutil.ReadFile(testInputTasklist)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if testTasklist, err := LoadFromReader(bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	} else {
+		data, err := ioutil.ReadFile(testExpectedOutput)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testExpected = string(data)
+		testGot = testTasklist.String()
+		if testGot != testExpected {
+			t.Errorf("Expected TaskList to be [%s], but got [%s]", testExpected, testGot)
+		}
+	}
+
+	if testTasklist, err := LoadFromReader(failingReader{}); testTasklist != nil || err == nil {
+		t.Errorf("Expected LoadFromReader to fail, but got TaskList back: [%s]", testTasklist)
+	}
+}
+
+func TestWriteTo(t *testing.T) {
+	if err := testTasklist.LoadFromPath(testInputTasklist); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTo(&testTasklist, &buf); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(testExpectedOutput)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testExpected = string(data)
+	testGot = buf.String()
+	if testGot != testExpected {
+		t.Errorf("Expected TaskList to be [%s], but got [%s]", testExpected, testGot)
+	}
+
+	var sb strings.Builder
+	n, err := testTasklist.WriteTo(&sb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testExpected = int64(len(data))
+	testGot = n
+	if testGot != testExpected {
+		t.Errorf("Expected %d bytes to be written, but got %d", testExpected, testGot)
+	}
+}
+
 func TestWriteFile(t *testing.T) {
 	_ = os.Remove(testOutput)
 	_, _ = os.Create(testOutput)
@@ -464,19 +529,19 @@ func TestTaskListRemoveTask(t *testing.T) {
 func TestTaskListReadErrors(t *testing.T) {
 	if testTasklist, err := LoadFromPath(testInputTasklistCreatedDateError); testTasklist != nil || err == nil {
 		t.Errorf("Expected LoadFromPath to fail because of invalid created date, but got TaskList back: [%s]", testTasklist)
-	} else if err.Error() != `parsing time "2013-13-01": month out of range` {
+	} else if err.Error() != `line 3, column 5: invalid created date: parsing time "2013-13-01": month out of range` {
 		t.Error(err)
 	}
 
 	if testTasklist, err := LoadFromPath(testInputTasklistDueDateError); testTasklist != nil || err == nil {
 		t.Errorf("Expected LoadFromPath to fail because of invalid due date, but got TaskList back: [%s]", testTasklist)
-	} else if err.Error() != `parsing time "2014-02-32": day out of range` {
+	} else if err.Error() != `line 4, column 77: invalid due date: parsing time "2014-02-32": day out of range` {
 		t.Error(err)
 	}
 
 	if testTasklist, err := LoadFromPath(testInputTasklistCompletedDateError); testTasklist != nil || err == nil {
 		t.Errorf("Expected LoadFromPath to fail because of invalid completed date, but got TaskList back: [%s]", testTasklist)
-	} else if err.Error() != `parsing time "2014-25-04": month out of range` {
+	} else if err.Error() != `line 6, column 3: invalid completed date: parsing time "2014-25-04": month out of range` {
 		t.Error(err)
 	}
 
@@ -487,3 +552,223 @@ func TestTaskListReadErrors(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestTaskListPreserveComments(t *testing.T) {
+	PreserveComments = true
+	defer func() { PreserveComments = false }()
+
+	if err := testTasklist.LoadFromPath(testInputTasklistComments); err != nil {
+		t.Fatal(err)
+	}
+
+	testExpected = 7
+	testGot = len(testTasklist)
+	if testGot != testExpected {
+		t.Errorf("Expected TaskList to contain %d entries, but got %d", testExpected, testGot)
+	}
+
+	kinds := []LineKind{LineComment, LineTask, LineTask, LineBlank, LineComment, LineTask, LineTask}
+	ids := []int{0, 1, 2, 0, 0, 3, 4}
+	for i, task := range testTasklist {
+		if task.Kind != kinds[i] {
+			t.Errorf("Expected entry[%d] to be of kind %v, but got %v", i, kinds[i], task.Kind)
+		}
+		if task.ID != ids[i] {
+			t.Errorf("Expected entry[%d] to have ID [%d], but got [%d]", i, ids[i], task.ID)
+		}
+	}
+
+	data, err := ioutil.ReadFile(testInputTasklistComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testExpected = string(data)
+	testGot = testTasklist.String()
+	if testGot != testExpected {
+		t.Errorf("Expected TaskList to be [%s], but got [%s]", testExpected, testGot)
+	}
+
+	if task, err := testTasklist.GetTask(0); err == nil || task != nil {
+		t.Errorf("Expected no Task to be found for ID 0, but got %v", task)
+	}
+	if err := testTasklist.RemoveTaskByID(0); err == nil {
+		t.Errorf("Expected no Task to be found for removal")
+	}
+
+	testExpected = 4
+	testGot = len(testTasklist.Filter(FilterNot(FilterCompleted), FilterCompleted))
+	if testGot != testExpected {
+		t.Errorf("Expected filtered TaskList to contain %d tasks, but got %d", testExpected, testGot)
+	}
+
+	if err := testTasklist.Sort(SortPriorityAsc); err != nil {
+		t.Fatal(err)
+	}
+	testExpected = `# Home
+(A) Call Mom @Phone +Family
+(B) Plan backyard herb garden @Home
+
+# Work
+(C) Add cover sheets @Office +TPSReports
+x 2014-01-02 Create golang library test cases @Go
+`
+	testGot = testTasklist.String()
+	if testGot != testExpected {
+		t.Errorf("Expected sorted TaskList to be [%s], but got [%s]", testExpected, testGot)
+	}
+
+	task := NewTask()
+	testTasklist.AddTask(&task)
+	testExpected = 5
+	testGot = task.ID
+	if testGot != testExpected {
+		t.Errorf("Expected new Task to have ID [%d], but got [%d]", testExpected, testGot)
+	}
+}
+
+func TestTaskListIgnoreComments(t *testing.T) {
+	if err := testTasklist.LoadFromPath(testInputTasklistComments); err != nil {
+		t.Fatal(err)
+	}
+
+	testExpected = 4
+	testGot = len(testTasklist)
+	if testGot != testExpected {
+		t.Errorf("Expected TaskList to contain %d tasks, but got %d", testExpected, testGot)
+	}
+}
+
+func TestLineKind(t *testing.T) {
+	names := map[LineKind]string{
+		LineTask:     "Task",
+		LineComment:  "Comment",
+		LineBlank:    "Blank",
+		LineUnparsed: "Unparsed",
+		10:           "LineKind(10)",
+	}
+	for k, s := range names {
+		if ss := k.String(); ss != s {
+			t.Errorf("Expected LineKind %d is %q, but got: %q", k, s, ss)
+		}
+	}
+}
+
+func TestTaskListParseError(t *testing.T) {
+	_, err := LoadFromPath(testInputTasklistDueDateError)
+	var perr *ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("Expected LoadFromPath to fail with ParseError, but got: %v", err)
+	}
+
+	testExpected = 4
+	testGot = perr.Line
+	if testGot != testExpected {
+		t.Errorf("Expected ParseError on line %d, but got %d", testExpected, testGot)
+	}
+	testExpected = 77
+	testGot = perr.Column
+	if testGot != testExpected {
+		t.Errorf("Expected ParseError on column %d, but got %d", testExpected, testGot)
+	}
+	testExpected = "2014-02-32"
+	testGot = perr.Token
+	if testGot != testExpected {
+		t.Errorf("Expected ParseError for token [%s], but got [%s]", testExpected, testGot)
+	}
+	testExpected = SegmentDueDate
+	testGot = perr.Field
+	if testGot != testExpected {
+		t.Errorf("Expected ParseError for field %v, but got %v", testExpected, testGot)
+	}
+	testExpected = "(B) 2013-12-01 private:false Outline chapter 5 +Novel @Computer Level:5 due:2014-02-32"
+	testGot = perr.Text
+	if testGot != testExpected {
+		t.Errorf("Expected ParseError for line [%s], but got [%s]", testExpected, testGot)
+	}
+	var terr *time.ParseError
+	if !errors.As(err, &terr) {
+		t.Errorf("Expected ParseError to wrap time.ParseError, but got: %v", perr.Err)
+	}
+}
+
+func TestTaskListLenientLoading(t *testing.T) {
+	LenientLoading = true
+	defer func() { LenientLoading = false }()
+
+	testTasklist, err := LoadFromPath(testInputTasklistLenient)
+	var perrs ParseErrors
+	if !errors.As(err, &perrs) {
+		t.Fatalf("Expected LoadFromPath to fail with ParseErrors, but got: %v", err)
+	}
+
+	testExpected = 3
+	testGot = len(perrs)
+	if testGot != testExpected {
+		t.Errorf("Expected %d parse errors, but got %d", testExpected, testGot)
+	}
+	lines := []int{2, 4, 5}
+	for i, perr := range perrs {
+		if perr.Line != lines[i] {
+			t.Errorf("Expected parse error[%d] on line %d, but got %d", i, lines[i], perr.Line)
+		}
+	}
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Line != 2 {
+		t.Errorf("Expected ParseErrors to unwrap to the first ParseError, but got: %v", perr)
+	}
+	var terr *time.ParseError
+	if !errors.As(err, &terr) || terr.Value != "2013-13-01" {
+		t.Errorf("Expected ParseErrors to unwrap to the first time.ParseError, but got: %v", terr)
+	}
+	if !errors.Is(err, perrs[2]) {
+		t.Errorf("Expected ParseErrors to match the last ParseError")
+	}
+	testExpected = `3 parse errors: line 2, column 5: invalid created date: parsing time "2013-13-01": month out of range; ` +
+		`line 4, column 3: invalid completed date: parsing time "2014-25-04": month out of range; ` +
+		`line 5, column 37: invalid due date: parsing time "2014-02-32": day out of range`
+	testGot = err.Error()
+	if testGot != testExpected {
+		t.Errorf("Expected error message [%s], but got [%s]", testExpected, testGot)
+	}
+
+	kinds := []LineKind{LineTask, LineUnparsed, LineTask, LineUnparsed, LineUnparsed}
+	ids := []int{1, 0, 2, 0, 0}
+	if len(testTasklist) != len(kinds) {
+		t.Fatalf("Expected TaskList to contain %d entries, but got %d", len(kinds), len(testTasklist))
+	}
+	for i, task := range testTasklist {
+		if task.Kind != kinds[i] {
+			t.Errorf("Expected entry[%d] to be of kind %v, but got %v", i, kinds[i], task.Kind)
+		}
+		if task.ID != ids[i] {
+			t.Errorf("Expected entry[%d] to have ID [%d], but got [%d]", i, ids[i], task.ID)
+		}
+	}
+
+	// unparsed lines are written back as they are
+	data, err := ioutil.ReadFile(testInputTasklistLenient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testExpected = string(data)
+	testGot = testTasklist.String()
+	if testGot != testExpected {
+		t.Errorf("Expected TaskList to be [%s], but got [%s]", testExpected, testGot)
+	}
+
+	testExpected = 2
+	testGot = len(testTasklist.Filter(FilterNotCompleted))
+	if testGot != testExpected {
+		t.Errorf("Expected filtered TaskList to contain %d tasks, but got %d", testExpected, testGot)
+	}
+
+	// other errors still abort loading
+	if testTasklist, err := LoadFromPath(testInputTasklistScannerError); testTasklist != nil || err == nil {
+		t.Errorf("Expected LoadFromPath to fail because of invalid file, but got TaskList back: [%s]", testTasklist)
+	}
+
+	// single error message
+	if _, err := LoadFromReader(strings.NewReader("Call Mom due:2014-02-32")); err == nil || err.Error() != `line 1, column 14: invalid due date: parsing time "2014-02-32": day out of range` {
+		t.Errorf("Expected LoadFromReader to fail with single parse error, but got: %v", err)
+	}
+}
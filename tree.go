@@ -0,0 +1,171 @@
+package todotxt
+
+import "time"
+
+// TaskNode represents a task with its subtasks in a tree of tasks built over a TaskList.
+// The Task pointers point into the TaskList, so they can be used to update the Tasks inside the TaskList.
+type TaskNode struct {
+	Task     *Task       // Task of the node.
+	Parent   *TaskNode   // Parent node, nil for root nodes.
+	Children []*TaskNode // Subtasks in the order of the TaskList.
+}
+
+// Tree returns the root nodes of the tree of tasks built from parent references given by "p:" tags, in the order of the TaskList.
+// Tasks without parent, with a reference to a missing task, or with a reference creating a cycle are root nodes.
+// Comment and blank lines are not included.
+func (tasklist *TaskList) Tree() []*TaskNode {
+	list := *tasklist
+	refs := newTaskRefs(list)
+	nodes := make([]*TaskNode, len(list))
+	for i := range list {
+		if list[i].IsTask() {
+			nodes[i] = &TaskNode{Task: &list[i]}
+		}
+	}
+
+	// parents are only set if they don't create a cycle
+	for _, node := range nodes {
+		if node == nil || !node.Task.HasParent() {
+			continue
+		}
+		if p := refs.resolve(node.Task.Parent()); p >= 0 && !nodes[p].hasAncestor(node) {
+			node.Parent = nodes[p]
+		}
+	}
+
+	var roots []*TaskNode
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+		if node.Parent == nil {
+			roots = append(roots, node)
+		} else {
+			node.Parent.Children = append(node.Parent.Children, node)
+		}
+	}
+	return roots
+}
+
+// TreeByIndent returns the root nodes of the tree of tasks built from the indentation of the task lines, in the order of the TaskList.
+// A task is a subtask of the nearest previous task with less leading whitespace characters. Tabs and spaces are counted as one character each.
+// Only tasks loaded or parsed from indented text have an indentation, which is written back if PreserveIndent is set.
+// Sorting the TaskList moves subtasks away from their parents, so the tree is lost. Comment and blank lines are not included.
+func (tasklist *TaskList) TreeByIndent() []*TaskNode {
+	var (
+		roots []*TaskNode
+		stack []*TaskNode
+	)
+	for i := range *tasklist {
+		task := &(*tasklist)[i]
+		if !task.IsTask() {
+			continue
+		}
+		node := &TaskNode{Task: task}
+		for len(stack) > 0 && len(stack[len(stack)-1].Task.indent) >= len(task.indent) {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) > 0 {
+			node.Parent = stack[len(stack)-1]
+			node.Parent.Children = append(node.Parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+		stack = append(stack, node)
+	}
+	return roots
+}
+
+// hasAncestor returns true if the given node is the node itself or one of its ancestors.
+func (node *TaskNode) hasAncestor(ancestor *TaskNode) bool {
+	for n := node; n != nil; n = n.Parent {
+		if n == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+// Depth returns the number of ancestors of the node, it's 0 for root nodes.
+func (node *TaskNode) Depth() int {
+	depth := 0
+	for n := node.Parent; n != nil; n = n.Parent {
+		depth++
+	}
+	return depth
+}
+
+// Walk calls fn for the node and all its descendants in depth-first order, i.e. each node before its children.
+// The descendants of a node are skipped if fn returns false for it.
+func (node *TaskNode) Walk(fn func(node *TaskNode) bool) {
+	if fn(node) {
+		for _, child := range node.Children {
+			child.Walk(fn)
+		}
+	}
+}
+
+// descendants returns all descendants of the node in depth-first order.
+func (node *TaskNode) descendants() []*TaskNode {
+	var nodes []*TaskNode
+	for _, child := range node.Children {
+		child.Walk(func(n *TaskNode) bool {
+			nodes = append(nodes, n)
+			return true
+		})
+	}
+	return nodes
+}
+
+// PercentComplete returns the percentage of completed tasks of all descendants of the node, from 0 to 100.
+// For nodes without children, it's 100 if the task of the node is completed, and 0 otherwise.
+func (node *TaskNode) PercentComplete() float64 {
+	nodes := node.descendants()
+	if len(nodes) == 0 {
+		if node.Task.Completed {
+			return 100
+		}
+		return 0
+	}
+	completed := 0
+	for _, n := range nodes {
+		if n.Task.Completed {
+			completed++
+		}
+	}
+	return float64(completed) * 100 / float64(len(nodes))
+}
+
+// EarliestDueDate returns the earliest due date of all descendants of the node which are not completed yet,
+// or zero time if none of them has a due date.
+func (node *TaskNode) EarliestDueDate() time.Time {
+	var earliest time.Time
+	for _, n := range node.descendants() {
+		if t := n.Task; !t.Completed && t.HasDueDate() && (earliest.IsZero() || t.DueDate.Before(earliest)) {
+			earliest = t.DueDate
+		}
+	}
+	return earliest
+}
+
+// HighestPriority returns the highest priority of all descendants of the node which are not completed yet,
+// or empty string if none of them has a priority.
+func (node *TaskNode) HighestPriority() string {
+	var highest string
+	for _, n := range node.descendants() {
+		if t := n.Task; !t.Completed && t.HasPriority() && (isEmpty(highest) || t.Priority < highest) {
+			highest = t.Priority
+		}
+	}
+	return highest
+}
+
+// Complete completes the task of the node, see Task.Complete(). If withChildren is 'true', all descendants are completed, too.
+func (node *TaskNode) Complete(withChildren bool) {
+	node.Task.Complete()
+	if withChildren {
+		for _, n := range node.descendants() {
+			n.Task.Complete()
+		}
+	}
+}
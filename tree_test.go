@@ -0,0 +1,143 @@
+package todotxt
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// treeString returns the tree of tasks as indented todo texts for testing.
+func treeString(roots []*TaskNode) string {
+	var sb strings.Builder
+	for _, root := range roots {
+		root.Walk(func(node *TaskNode) bool {
+			sb.WriteString(strings.Repeat("  ", node.Depth()) + node.Task.Todo + "\n")
+			return true
+		})
+	}
+	return sb.String()
+}
+
+func TestTaskListTree(t *testing.T) {
+	tasklist, err := LoadFromReader(strings.NewReader(`Release id:rel
+(C) Write code p:rel due:2020-03-01
+x (A) Write tests p:2 due:2020-01-01
+(B) Write docs p:rel due:2020-02-01
+Fix bug p:2
+Orphan p:missing
+Loop A id:la p:lb
+Loop B id:lb p:la
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	roots := tasklist.Tree()
+	testExpected = "Release\n  Write code\n    Write tests\n    Fix bug\n  Write docs\nOrphan\nLoop B\n  Loop A\n"
+	testGot = treeString(roots)
+	if testGot != testExpected {
+		t.Errorf("Expected tree to be [%s], but got [%s]", testExpected, testGot)
+	}
+
+	release := roots[0]
+	testExpected = 25.0
+	testGot = release.PercentComplete()
+	if testGot != testExpected {
+		t.Errorf("Expected percent complete to be %v, but got %v", testExpected, testGot)
+	}
+	testExpected = time.Date(2020, 2, 1, 0, 0, 0, 0, time.Local)
+	testGot = release.EarliestDueDate()
+	if testGot != testExpected {
+		t.Errorf("Expected earliest due date to be %v, but got %v", testExpected, testGot)
+	}
+	testExpected = "B"
+	testGot = release.HighestPriority()
+	if testGot != testExpected {
+		t.Errorf("Expected highest priority to be %v, but got %v", testExpected, testGot)
+	}
+
+	// leaf nodes
+	leaf := release.Children[1]
+	if leaf.PercentComplete() != 0 || !leaf.EarliestDueDate().IsZero() || leaf.HighestPriority() != "" {
+		t.Errorf("Expected no rollups for leaf node, but got %v, %v, %v", leaf.PercentComplete(), leaf.EarliestDueDate(), leaf.HighestPriority())
+	}
+	if release.Children[0].Children[0].PercentComplete() != 100 {
+		t.Errorf("Expected completed leaf node to be 100 percent complete")
+	}
+
+	// completion updates the TaskList
+	release.Children[0].Complete(false)
+	if !tasklist[1].Completed || tasklist[4].Completed {
+		t.Errorf("Expected only parent task to be completed")
+	}
+	release.Complete(true)
+	for i := 0; i < 5; i++ {
+		if !tasklist[i].Completed {
+			t.Errorf("Expected task %d to be completed", tasklist[i].ID)
+		}
+	}
+	if tasklist[5].Completed {
+		t.Errorf("Expected task %d not to be completed", tasklist[5].ID)
+	}
+}
+
+func TestTaskListTreeByIndent(t *testing.T) {
+	IgnoreComments, PreserveComments = true, true
+	defer func() { PreserveComments = false }()
+	tasklist, err := LoadFromReader(strings.NewReader(`Epic
+  Story A
+    Task 1
+
+    Task 2
+  # comment
+  Story B
+	Story C
+Another epic
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	roots := tasklist.TreeByIndent()
+	testExpected = "Epic\n  Story A\n    Task 1\n    Task 2\n  Story B\n  Story C\nAnother epic\n"
+	testGot = treeString(roots)
+	if testGot != testExpected {
+		t.Errorf("Expected tree to be [%s], but got [%s]", testExpected, testGot)
+	}
+
+	// indentation is only kept when writing with PreserveIndent
+	testExpected = "Epic\nStory A\nTask 1\n\nTask 2\n  # comment\nStory B\nStory C\nAnother epic\n"
+	testGot = tasklist.String()
+	if testGot != testExpected {
+		t.Errorf("Expected TaskList to be [%s], but got [%s]", testExpected, testGot)
+	}
+	PreserveIndent = true
+	defer func() { PreserveIndent = false }()
+	testExpected = "Epic\n  Story A\n    Task 1\n\n    Task 2\n  # comment\n  Story B\n\tStory C\nAnother epic\n"
+	testGot = tasklist.String()
+	if testGot != testExpected {
+		t.Errorf("Expected TaskList to be [%s], but got [%s]", testExpected, testGot)
+	}
+	reloaded, err := LoadFromReader(strings.NewReader(tasklist.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testExpected = treeString(roots)
+	testGot = treeString(reloaded.TreeByIndent())
+	if testGot != testExpected {
+		t.Errorf("Expected reloaded tree to be [%s], but got [%s]", testExpected, testGot)
+	}
+
+	// walk can skip descendants
+	var visited []string
+	roots[0].Walk(func(node *TaskNode) bool {
+		visited = append(visited, node.Task.Todo)
+		return node.Depth() == 0
+	})
+	testExpected = "[Epic Story A Story B Story C]"
+	testGot = fmt.Sprint(visited)
+	if testGot != testExpected {
+		t.Errorf("Expected visited nodes to be %s, but got %s", testExpected, testGot)
+	}
+}
@@ -0,0 +1,178 @@
+package todotxt
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// UIDTag is the key of the additional tag holding the unique identifier of a task, e.g. "id:3f2a9c1".
+//
+// Unlike Task.ID, which is the position of the task assigned by loading and AddTask(), the unique identifier is stored in the task string,
+// so it survives sorting, filtering, writing and editing of the todo.txt file elsewhere.
+const UIDTag = "id"
+
+// maxUIDAttempts is the maximum number of attempts to generate a unique identifier not used by other tasks.
+const maxUIDAttempts = 10
+
+// UIDGenerator generates unique identifiers for tasks.
+type UIDGenerator interface {
+	NewUID(task *Task) (string, error)
+}
+
+// UIDGeneratorFunc is an adapter to allow the use of ordinary functions as UIDGenerator.
+type UIDGeneratorFunc func(task *Task) (string, error)
+
+// NewUID returns f(task).
+func (f UIDGeneratorFunc) NewUID(task *Task) (string, error) {
+	return f(task)
+}
+
+var (
+	// UUIDGenerator generates random (version 4) UUIDs, e.g. "1b4e28ba-2fa1-4d2b-883f-0016d3cca427". It's used if no other UIDGenerator is given.
+	UUIDGenerator UIDGenerator = UIDGeneratorFunc(newUUID)
+
+	// ULIDGenerator generates ULIDs, e.g. "01ARZ3NDEKTSV4RRFFQ69G5FAV", which are sorted by the current time of the task's Clock.
+	ULIDGenerator UIDGenerator = UIDGeneratorFunc(newULID)
+
+	// ShortHashGenerator generates 8 hex digits of the hash of the task string and a random salt, e.g. "3f2a9c1e".
+	ShortHashGenerator UIDGenerator = UIDGeneratorFunc(newShortHash)
+)
+
+// newUUID returns a random UUID.
+func newUUID(*Task) (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return emptyStr, err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40 // Version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // Variant RFC 4122
+	h := hex.EncodeToString(b[:])
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:], nil
+}
+
+// crockfordBase32 is the alphabet of ULIDs.
+const crockfordBase32 = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// newULID returns a ULID with the current time of the task's Clock.
+func newULID(task *Task) (string, error) {
+	var b [16]byte
+	ms := uint64(task.now().UnixNano() / 1e6)
+	binary.BigEndian.PutUint16(b[0:2], uint16(ms>>32))
+	binary.BigEndian.PutUint32(b[2:6], uint32(ms))
+	if _, err := rand.Read(b[6:]); err != nil {
+		return emptyStr, err
+	}
+
+	// encode 128 bits as 26 characters of 5 bits, with 2 leading zero bits
+	hi, lo := binary.BigEndian.Uint64(b[0:8]), binary.BigEndian.Uint64(b[8:16])
+	var s [26]byte
+	for i := len(s) - 1; i >= 0; i-- {
+		s[i] = crockfordBase32[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(s[:]), nil
+}
+
+// newShortHash returns a short hash of the task string and a random salt.
+func newShortHash(task *Task) (string, error) {
+	var salt [8]byte
+	if _, err := rand.Read(salt[:]); err != nil {
+		return emptyStr, err
+	}
+	sum := sha256.Sum256(append([]byte(task.canonical()), salt[:]...))
+	return hex.EncodeToString(sum[:4]), nil
+}
+
+// UID returns the unique identifier of the task given by the "id:" tag, or empty string if the task has none.
+func (task *Task) UID() string {
+	return task.AdditionalTags[UIDTag]
+}
+
+// HasUID returns true if the task has a unique identifier.
+func (task *Task) HasUID() bool {
+	return isNotEmpty(task.UID())
+}
+
+// SetUID sets the unique identifier of the task as "id:" tag. An empty uid removes the tag.
+// Returns an error if the uid contains whitespaces or colons, which are not allowed in tag values.
+func (task *Task) SetUID(uid string) error {
+	if isEmpty(uid) {
+		delete(task.AdditionalTags, UIDTag)
+		return nil
+	}
+	if strings.ContainsAny(uid, whitespaces+":") {
+		return fmt.Errorf("invalid task uid: %q", uid)
+	}
+	if task.AdditionalTags == nil {
+		task.AdditionalTags = make(map[string]string)
+	}
+	task.AdditionalTags[UIDTag] = uid
+	return nil
+}
+
+// GetTaskByUID returns a Task by given unique identifier from the TaskList. The returned Task pointer can be used to update the Task inside the TaskList.
+// Returns an error if Task could not be found.
+func (tasklist *TaskList) GetTaskByUID(uid string) (*Task, error) {
+	if isNotEmpty(uid) {
+		for i := range *tasklist {
+			if t := &([]Task(*tasklist))[i]; t.IsTask() && t.UID() == uid {
+				return t, nil
+			}
+		}
+	}
+	return nil, errors.New("task not found")
+}
+
+// AssignUIDs sets a new unique identifier for all tasks in the TaskList without one, and returns the number of assigned identifiers.
+// Generated identifiers already used by other tasks are discarded and generated again.
+// If gen is nil, UUIDGenerator is used. Existing identifiers are never changed.
+func (tasklist *TaskList) AssignUIDs(gen UIDGenerator) (int, error) {
+	if gen == nil {
+		gen = UUIDGenerator
+	}
+
+	used := make(map[string]bool)
+	for i := range *tasklist {
+		if t := &(*tasklist)[i]; t.IsTask() && t.HasUID() {
+			used[t.UID()] = true
+		}
+	}
+
+	count := 0
+	for i := range *tasklist {
+		task := &(*tasklist)[i]
+		if !task.IsTask() || task.HasUID() {
+			continue
+		}
+		uid, err := newUniqueUID(gen, task, used)
+		if err != nil {
+			return count, err
+		}
+		if err := task.SetUID(uid); err != nil {
+			return count, err
+		}
+		used[uid] = true
+		count++
+	}
+	return count, nil
+}
+
+// newUniqueUID generates a new unique identifier for the task, which is not in the used set.
+func newUniqueUID(gen UIDGenerator, task *Task, used map[string]bool) (string, error) {
+	for i := 0; i < maxUIDAttempts; i++ {
+		uid, err := gen.NewUID(task)
+		if err != nil {
+			return emptyStr, err
+		}
+		if isNotEmpty(uid) && !used[uid] {
+			return uid, nil
+		}
+	}
+	return emptyStr, errors.New("failed to generate unique task uid")
+}
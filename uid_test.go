@@ -0,0 +1,137 @@
+package todotxt
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUIDGenerators(t *testing.T) {
+	task, _ := ParseTask("Call Mom")
+	task.SetClock(FixedClock(time.Unix(0, 1469918176385*int64(time.Millisecond))))
+
+	for _, tc := range []struct {
+		name string
+		gen  UIDGenerator
+		rx   *regexp.Regexp
+	}{
+		{"UUID", UUIDGenerator, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)},
+		{"ULID", ULIDGenerator, regexp.MustCompile(`^01ARYZ6S41[0-9A-HJKMNP-TV-Z]{16}$`)},
+		{"ShortHash", ShortHashGenerator, regexp.MustCompile(`^[0-9a-f]{8}$`)},
+	} {
+		uid, err := tc.gen.NewUID(task)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !tc.rx.MatchString(uid) {
+			t.Errorf("Expected %s to match %s, but got %s", tc.name, tc.rx, uid)
+		}
+		if other, _ := tc.gen.NewUID(task); other == uid {
+			t.Errorf("Expected %s to be unique, but got %s twice", tc.name, uid)
+		}
+	}
+}
+
+func TestTaskUID(t *testing.T) {
+	task, _ := ParseTask("Call Mom id:abc")
+	testExpected = "abc"
+	testGot = task.UID()
+	if testGot != testExpected {
+		t.Errorf("Expected Task UID to be [%s], but got [%s]", testExpected, testGot)
+	}
+
+	task, _ = ParseTask("Call Mom")
+	if task.HasUID() {
+		t.Errorf("Expected Task to have no UID, but got [%s]", task.UID())
+	}
+	if err := task.SetUID("x1"); err != nil {
+		t.Fatal(err)
+	}
+	testExpected = "Call Mom id:x1"
+	testGot = task.String()
+	if testGot != testExpected {
+		t.Errorf("Expected Task to be [%s], but got [%s]", testExpected, testGot)
+	}
+	for _, uid := range []string{"a b", "a:b"} {
+		if err := task.SetUID(uid); err == nil {
+			t.Errorf("Expected SetUID to fail for [%s]", uid)
+		}
+	}
+	if err := task.SetUID(""); err != nil || task.HasUID() {
+		t.Errorf("Expected SetUID to remove UID, but got [%s], %v", task.UID(), err)
+	}
+}
+
+func TestAssignUIDs(t *testing.T) {
+	IgnoreComments, PreserveComments = true, true
+	defer func() { PreserveComments = false }()
+	tasklist, err := LoadFromReader(strings.NewReader("# comment\nCall Mom id:1\nBuy milk\nPay rent due:2020-02-01\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// generated duplicates are discarded
+	n := 0
+	gen := UIDGeneratorFunc(func(*Task) (string, error) {
+		n++
+		return []string{"1", "2", "2", "3"}[n-1], nil
+	})
+	count, err := tasklist.AssignUIDs(gen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testExpected = 2
+	testGot = count
+	if testGot != testExpected {
+		t.Errorf("Expected %d assigned UIDs, but got %d", testExpected, testGot)
+	}
+	testExpected = "# comment\nCall Mom id:1\nBuy milk id:2\nPay rent id:3 due:2020-02-01\n"
+	testGot = tasklist.String()
+	if testGot != testExpected {
+		t.Errorf("Expected TaskList to be [%s], but got [%s]", testExpected, testGot)
+	}
+
+	// UIDs survive sort, filter and write
+	if err := tasklist.Sort(SortTodoTextAsc); err != nil {
+		t.Fatal(err)
+	}
+	filtered := tasklist.Filter(FilterNot(FilterHasDueDate))
+	dir, cleanup := testTempDir(t)
+	defer cleanup()
+	filename := filepath.Join(dir, "todo.txt")
+	if err := filtered.WriteToPath(filename); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadFromPath(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err := loaded.GetTaskByUID("2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testExpected = "Buy milk"
+	testGot = task.Todo
+	if testGot != testExpected {
+		t.Errorf("Expected Task to be [%s], but got [%s]", testExpected, testGot)
+	}
+	if _, err := loaded.GetTaskByUID("3"); err == nil {
+		t.Errorf("Expected no Task with UID 3")
+	}
+	if _, err := loaded.GetTaskByUID(""); err == nil {
+		t.Errorf("Expected no Task with empty UID")
+	}
+
+	// default generator and failing generator
+	tasklist = TaskList{{Todo: "A"}, {Todo: "B"}}
+	if count, err = tasklist.AssignUIDs(nil); err != nil || count != 2 || tasklist[0].UID() == tasklist[1].UID() {
+		t.Errorf("Expected 2 unique UIDs, but got %d: %v", count, err)
+	}
+	tasklist = TaskList{{Todo: "A"}, {Todo: "B"}}
+	constant := UIDGeneratorFunc(func(*Task) (string, error) { return "same", nil })
+	if count, err = tasklist.AssignUIDs(constant); err == nil || count != 1 {
+		t.Errorf("Expected AssignUIDs to fail after 1 UID, but got %d: %v", count, err)
+	}
+}
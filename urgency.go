@@ -0,0 +1,168 @@
+package todotxt
+
+import (
+	"math"
+	"sort"
+)
+
+// UrgencyCoefficients represents the weights of the factors of the urgency score of tasks, similar to Taskwarrior.
+// Each factor is a value from 0 to 1, multiplied by its coefficient, and the urgency is the sum of all of them.
+type UrgencyCoefficients struct {
+	Priority float64 // Priority factor: 1 for (A), 0.65 for (B), 0.3 for (C), and 0 for lower or no priority.
+	Due      float64 // Due factor: 1 for tasks overdue by 7 days or more, 0.2 for tasks due in 14 days or more, linear in between, and 0 without due date.
+	Age      float64 // Age factor: days since the created date divided by 365, up to 1, and 0 without created date.
+	Project  float64 // Project factor: 1 for tasks with any project.
+	Context  float64 // Context factor: 1 for tasks with any context.
+	Blocking float64 // Blocking factor: 1 for tasks blocking other tasks not completed yet, via "dep:" or "p:" tags.
+	Blocked  float64 // Blocked factor: 1 for tasks blocked by other tasks not completed yet, usually with a negative coefficient.
+}
+
+// DefaultUrgency holds the urgency coefficients for Task.Urgency(), TaskList.Urgency() and sorting with SortUrgencyAsc and SortUrgencyDesc.
+var DefaultUrgency = UrgencyCoefficients{
+	Priority: 6.0,
+	Due:      12.0,
+	Age:      2.0,
+	Project:  1.0,
+	Context:  1.0,
+	Blocking: 8.0,
+	Blocked:  -5.0,
+}
+
+// Urgency returns the urgency score of the task with DefaultUrgency coefficients, according to the Clock of the task.
+// Blocking relations are not considered, see TaskList.Urgency() for them. Completed tasks have no urgency.
+func (task *Task) Urgency() float64 {
+	return DefaultUrgency.score(task, false, false)
+}
+
+// Urgency returns the urgency score of the task in the TaskList with DefaultUrgency coefficients, including blocking relations.
+// See UrgencyCoefficients.Urgency() for further information.
+func (tasklist *TaskList) Urgency(task *Task) float64 {
+	return DefaultUrgency.Urgency(task, *tasklist)
+}
+
+// Urgency returns the urgency score of the task with the coefficients, according to the Clock of the task.
+// If tasklist is not nil, tasks blocking or blocked by other tasks in it get the Blocking and Blocked factors.
+// Completed tasks have no urgency.
+func (c UrgencyCoefficients) Urgency(task *Task, tasklist TaskList) float64 {
+	var blocked, blocking bool
+	if tasklist != nil {
+		blocked = tasklist.IsBlocked(task)
+		refs := newTaskRefs(tasklist)
+		for i := range tasklist {
+			if t := &tasklist[i]; t.IsTask() && !t.Completed {
+				indexes, _ := tasklist.blockerIndexes(refs, t)
+				for _, j := range indexes {
+					blocking = blocking || tasklist.isSameEntry(j, task)
+				}
+			}
+		}
+	}
+	return c.score(task, blocked, blocking)
+}
+
+// score returns the urgency score of the task with the given blocking status.
+func (c UrgencyCoefficients) score(task *Task, blocked, blocking bool) float64 {
+	if !task.IsTask() || task.Completed {
+		return 0
+	}
+	factor := func(b bool) float64 {
+		if b {
+			return 1
+		}
+		return 0
+	}
+	return c.Priority*priorityFactor(task.Priority) +
+		c.Due*task.dueFactor() +
+		c.Age*task.ageFactor() +
+		c.Project*factor(task.HasProjects()) +
+		c.Context*factor(task.HasContexts()) +
+		c.Blocking*factor(blocking) +
+		c.Blocked*factor(blocked)
+}
+
+// priorityFactor returns the urgency factor of the priority.
+func priorityFactor(priority string) float64 {
+	switch priority {
+	case "A":
+		return 1.0
+	case "B":
+		return 0.65
+	case "C":
+		return 0.3
+	}
+	return 0
+}
+
+// dueFactor returns the urgency factor of the due date of the task.
+func (task *Task) dueFactor() float64 {
+	if !task.HasDueDate() {
+		return 0
+	}
+	overdue := float64(daysBetween(task.DueDate, task.now())) // Days overdue, negative if due in the future
+	switch {
+	case overdue >= 7:
+		return 1.0
+	case overdue >= -14:
+		return (overdue+14)*0.8/21 + 0.2
+	}
+	return 0.2
+}
+
+// ageFactor returns the urgency factor of the age of the task.
+func (task *Task) ageFactor() float64 {
+	if !task.HasCreatedDate() {
+		return 0
+	}
+	return math.Max(0, math.Min(1, float64(daysBetween(task.CreatedDate, task.now()))/365))
+}
+
+// sortUrgency is a SortKey of the urgency score with custom coefficients.
+type sortUrgency struct {
+	c    UrgencyCoefficients
+	desc bool
+}
+
+// SortUrgency returns a SortKey sorting tasks by the urgency score with the coefficients, including blocking relations,
+// in descending order if desc is true. SortUrgencyAsc and SortUrgencyDesc sort with DefaultUrgency coefficients.
+func SortUrgency(c UrgencyCoefficients, desc bool) SortKey {
+	return sortUrgency{c: c, desc: desc}
+}
+
+func (su sortUrgency) sortTaskList(tasklist *TaskList) error {
+	tasklist.sortByUrgency(su.c, su.desc)
+	return nil
+}
+
+// sortByUrgency sorts tasks by the urgency score with the coefficients, including blocking relations.
+func (tasklist *TaskList) sortByUrgency(c UrgencyCoefficients, desc bool) *TaskList {
+	list := *tasklist
+	blocked, blocking := list.blockingStatus()
+
+	scores := make([]float64, len(list))
+	for i := range list {
+		if list[i].IsTask() {
+			scores[i] = c.score(&list[i], blocked[i], blocking[i])
+		}
+	}
+
+	// sort tasks of each section, and leave other lines where they are
+	for _, r := range list.sections() {
+		sorted := make([]int, 0, r[1]-r[0])
+		for i := r[0]; i < r[1]; i++ {
+			sorted = append(sorted, i)
+		}
+		sort.SliceStable(sorted, func(l, r int) bool {
+			if desc {
+				return scores[sorted[l]] > scores[sorted[r]]
+			}
+			return scores[sorted[l]] < scores[sorted[r]]
+		})
+
+		tasks := make([]Task, len(sorted))
+		for i, pos := range sorted {
+			tasks[i] = list[pos]
+		}
+		copy(list[r[0]:r[1]], tasks)
+	}
+	return tasklist
+}
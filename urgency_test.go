@@ -0,0 +1,140 @@
+package todotxt
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTaskUrgency(t *testing.T) {
+	now := time.Date(2020, 1, 15, 12, 0, 0, 0, time.Local)
+	for _, tc := range []struct {
+		text     string
+		expected float64
+	}{
+		{"Plain task", 0},
+		{"(A) Task", 6.0},
+		{"(B) Task", 3.9},
+		{"(C) Task", 1.8},
+		{"(D) Task", 0},
+		{"Task due:2020-01-08", 12.0},
+		{"Task due:2020-01-15", 12.0 * (14*0.8/21 + 0.2)},
+		{"Task due:2020-01-29", 12.0 * 0.2},
+		{"Task due:2020-03-01", 12.0 * 0.2},
+		{"2019-07-19 Task", 2.0 * 180 / 365},
+		{"2018-01-01 Task", 2.0},
+		{"Task +Project @context", 2.0},
+		{"x (A) Task +Project due:2020-01-01", 0},
+	} {
+		task, err := ParseTask(tc.text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		task.SetClock(FixedClock(now))
+		if got := task.Urgency(); math.Abs(got-tc.expected) > 1e-9 {
+			t.Errorf("Expected urgency of [%s] to be %v, but got %v", tc.text, tc.expected, got)
+		}
+	}
+
+	// custom coefficients
+	task, _ := ParseTask("(A) Task +Project")
+	coeffs := UrgencyCoefficients{Priority: 1, Project: 10}
+	testExpected = 11.0
+	testGot = coeffs.Urgency(task, nil)
+	if testGot != testExpected {
+		t.Errorf("Expected urgency to be %v, but got %v", testExpected, testGot)
+	}
+}
+
+func TestTaskListUrgency(t *testing.T) {
+	tasklist, err := LoadFromReader(strings.NewReader(`(A) Release id:rel dep:2
+Write code
+Write docs p:rel
+x Old task dep:2
+(C) Unrelated @home
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tasklist.SetClock(FixedClock(time.Date(2020, 1, 15, 12, 0, 0, 0, time.Local)))
+
+	for i, expected := range []float64{6.0 - 5.0, 8.0, 8.0, 0, 1.8 + 1.0} {
+		if got := tasklist.Urgency(&tasklist[i]); math.Abs(got-expected) > 1e-9 {
+			t.Errorf("Expected urgency of task %d to be %v, but got %v", tasklist[i].ID, expected, got)
+		}
+	}
+
+	if err := tasklist.Sort(SortUrgencyDesc); err != nil {
+		t.Fatal(err)
+	}
+	var ids []int
+	for _, task := range tasklist {
+		ids = append(ids, task.ID)
+	}
+	testExpected = "[2 3 5 1 4]"
+	testGot = fmt.Sprint(ids)
+	if testGot != testExpected {
+		t.Errorf("Expected tasks to be sorted as %s, but got %s", testExpected, testGot)
+	}
+
+	if err := tasklist.Sort(SortUrgencyAsc, SortTaskIDAsc); err != nil {
+		t.Fatal(err)
+	}
+	ids = nil
+	for _, task := range tasklist {
+		ids = append(ids, task.ID)
+	}
+	testExpected = "[4 1 5 2 3]"
+	testGot = fmt.Sprint(ids)
+	if testGot != testExpected {
+		t.Errorf("Expected tasks to be sorted as %s, but got %s", testExpected, testGot)
+	}
+
+	// tasks are sorted within their sections
+	IgnoreComments, PreserveComments = true, true
+	defer func() { PreserveComments = false }()
+	tasklist, err = LoadFromReader(strings.NewReader("# Later\nSomeday\n(A) Soon\n# Now\nLater\n(B) Now\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tasklist.Sort(SortUrgencyDesc); err != nil {
+		t.Fatal(err)
+	}
+	testExpected = "# Later\n(A) Soon\nSomeday\n# Now\n(B) Now\nLater\n"
+	testGot = tasklist.String()
+	if testGot != testExpected {
+		t.Errorf("Expected sections to be sorted on their own as [%s], but got [%s]", testExpected, testGot)
+	}
+}
+
+func TestSortUrgency(t *testing.T) {
+	tasklist, err := LoadFromReader(strings.NewReader("(A) Release +app\n(C) Call mom @phone\nWater plants\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := UrgencyCoefficients{Priority: 1.0, Context: 10.0}
+	for _, tc := range []struct {
+		key      SortKey
+		expected string
+	}{
+		{SortUrgency(c, true), "[2 1 3]"},
+		{SortUrgency(c, false), "[3 1 2]"},
+		{SortUrgency(DefaultUrgency, true), "[1 2 3]"},
+	} {
+		if err := tasklist.SortBy(tc.key, SortTaskIDAsc); err != nil {
+			t.Fatal(err)
+		}
+		var ids []int
+		for _, task := range tasklist {
+			ids = append(ids, task.ID)
+		}
+		testExpected = tc.expected
+		testGot = fmt.Sprint(ids)
+		if testGot != testExpected {
+			t.Errorf("Expected tasks to be sorted as %s, but got %s", testExpected, testGot)
+		}
+	}
+}